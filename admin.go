@@ -0,0 +1,125 @@
+package launch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
+
+// Bounds the graceful stages of a shutdown started by `POST /stop/{component}`, as the client isn't left waiting on it
+// to give up instead.
+const adminStopComponentTimeout = 30 * time.Second
+
+// An AdminStopError is the reason passed to [Controller.RequestStop] when a stop is requested via [AdminHandler].
+type AdminStopError struct {
+	// The address of the client that made the request, as given by [http.Request.RemoteAddr].
+	RemoteAddr string
+}
+
+func (e AdminStopError) Error() string {
+	return "stop requested via admin handler by " + e.RemoteAddr
+}
+
+// An AdminError is a single entry in the response to `GET /errors` from [AdminHandler].
+type AdminError struct {
+	// The component and stage the error came from, if it came from a component (as opposed to, say,
+	// [Controller.RequestStop]).
+	Component string `json:"component,omitempty"`
+	Stage     string `json:"stage,omitempty"`
+
+	// The error message, without the component and stage.
+	Error string `json:"error"`
+}
+
+// AdminHandler returns an [http.Handler] for inspecting and managing the controller:
+//
+//   - `GET /status` responds with the controller's [Status], as JSON.
+//   - `GET /errors` responds with [Controller.AllErrors] as a JSON list of [AdminError].
+//   - `POST /stop` calls [Controller.RequestStop] with an [AdminStopError], and responds with 202 Accepted without
+//     waiting for the shutdown.
+//   - `POST /stop/{component}` calls [Controller.StopComponent], and responds with 202 Accepted without waiting for
+//     the component to be stopped, as it may well be the server that's handling the request. Its graceful shutdown
+//     is limited to 30s. Unknown components result in 404 Not Found, and a controller that isn't running in 409
+//     Conflict. A failed shutdown is logged, and the component's state can be followed via `GET /status`.
+//
+// Failed requests respond with a JSON object holding an "error" message.
+//
+// The handler doesn't do any authentication of its own, so it should be wrapped in a middleware that does, or be
+// served on an address that's only reachable by operators. It only serves the exact paths above; use
+// [http.StripPrefix] to mount it elsewhere.
+func AdminHandler(ctrl Controller) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, ctrl.Status())
+	})
+	mux.HandleFunc("GET /errors", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, http.StatusOK, adminErrors(ctrl.AllErrors()))
+	})
+	mux.HandleFunc("POST /stop", func(w http.ResponseWriter, r *http.Request) {
+		ctrl.RequestStop(AdminStopError{r.RemoteAddr})
+		writeAdminJSON(w, http.StatusAccepted, map[string]string{"result": "stopping"})
+	})
+	mux.HandleFunc("POST /stop/{component}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("component")
+		if err := adminCheckStoppable(ctrl.Status(), name); err != nil {
+			code := http.StatusNotFound
+			if errors.Is(err, lcerrors.ErrControllerNotAlive) {
+				code = http.StatusConflict
+			}
+			writeAdminJSON(w, code, map[string]string{"error": err.Error()})
+			return
+		}
+
+		// Stopping the server that's handling this request would otherwise wait on the request itself. The client
+		// going away shouldn't cut the component's shutdown short either.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), adminStopComponentTimeout)
+		go func() {
+			defer cancel()
+			if err := ctrl.StopComponent(ctx, name); err != nil {
+				ctrl.impl.Log.Warn("failed to stop component via admin handler", "component", name, "error", err)
+			}
+		}()
+		writeAdminJSON(w, http.StatusAccepted, map[string]string{"result": "stopping"})
+	})
+	return mux
+}
+
+// Reports the same errors as [Controller.StopComponent] would for a controller that isn't running, or an unknown
+// component, so that they can be answered before stopping it in the background.
+func adminCheckStoppable(s Status, name string) error {
+	if s.State != "Alive" {
+		return lcerrors.ErrControllerNotAlive
+	}
+	for _, cs := range s.Components {
+		if cs.Name == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("component %q: %w", name, lcerrors.ErrUnknownComponent)
+}
+
+func adminErrors(errs []error) []AdminError {
+	out := make([]AdminError, 0, len(errs))
+	for _, err := range errs {
+		var ce lcerrors.ComponentError
+		if errors.As(err, &ce) {
+			out = append(out, AdminError{Component: ce.Name, Stage: ce.Stage, Error: ce.Err.Error()})
+		} else {
+			out = append(out, AdminError{Error: err.Error()})
+		}
+	}
+	return out
+}
+
+func writeAdminJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
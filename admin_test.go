@@ -0,0 +1,114 @@
+package launch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/synctest"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func TestAdminHandler(t *testing.T) {
+	serve := func(h http.Handler, method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, target, nil)) // from 192.0.2.1:1234
+		return w
+	}
+	decode := func(t *testing.T, w *httptest.ResponseRecorder, v any) {
+		t.Helper()
+		test.Eq(t, "application/json", w.Header().Get("Content-Type"))
+		must.NoError(t, json.Unmarshal(w.Body.Bytes(), v))
+	}
+	nopStartStop := func() ComponentOption {
+		return WithStartStop(
+			func(context.Context) error { return nil },
+			func(context.Context) error { return nil })
+	}
+
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := NewController(t.Context())
+		h := AdminHandler(ctrl)
+
+		ctrl.Launch("a", nopStartStop())
+		ctrl.Launch("b", nopStartStop())
+		ctrl.Launch("cache",
+			nopStartStop(),
+			WithCriticality(Optional),
+			WithCheckReady(func(ctx context.Context) (bool, error) { return false, errors.New("nope") }))
+
+		w := serve(h, http.MethodGet, "/status")
+		test.Eq(t, http.StatusOK, w.Code)
+		var status Status
+		decode(t, w, &status)
+		test.Eq(t, ctrl.Status(), status)
+
+		w = serve(h, http.MethodPost, "/stop/b")
+		test.Eq(t, http.StatusAccepted, w.Code)
+		test.Eq(t, "{\"result\":\"stopping\"}\n", w.Body.String())
+		synctest.Wait()
+		test.SliceLen(t, 2, ctrl.Status().Components)
+
+		w = serve(h, http.MethodPost, "/stop/nope")
+		test.Eq(t, http.StatusNotFound, w.Code)
+		test.Eq(t, "{\"error\":\"component \\\"nope\\\": unknown component\"}\n", w.Body.String())
+
+		w = serve(h, http.MethodGet, "/stop")
+		test.Eq(t, http.StatusMethodNotAllowed, w.Code)
+
+		w = serve(h, http.MethodPost, "/stop")
+		test.Eq(t, http.StatusAccepted, w.Code)
+		ctrl.Wait()
+		var stopErr AdminStopError
+		must.ErrorAs(t, ctrl.AllErrors()[1], &stopErr)
+		test.Eq(t, "192.0.2.1:1234", stopErr.RemoteAddr)
+
+		w = serve(h, http.MethodGet, "/errors")
+		test.Eq(t, http.StatusOK, w.Code)
+		var errs []AdminError
+		decode(t, w, &errs)
+		test.Eq(t, []AdminError{
+			{Component: "cache", Stage: "wait-ready", Error: "nope"},
+			{Error: "stop requested via admin handler by 192.0.2.1:1234"},
+		}, errs)
+
+		w = serve(h, http.MethodPost, "/stop/a")
+		test.Eq(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestAdminHandler_stopSelf(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := NewController(t.Context())
+		h := AdminHandler(ctrl)
+
+		// Stands in for the HTTP server that serves the admin handler, whose shutdown waits for the requests it's
+		// handling to finish.
+		requestDone := make(chan struct{})
+		admin := ctrl.Launch("admin", WithStartStop(
+			func(context.Context) error { return nil },
+			func(context.Context) error { <-requestDone; return nil }))
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/stop/admin", nil))
+		close(requestDone)
+		test.Eq(t, http.StatusAccepted, w.Code)
+
+		<-admin.Done()
+		test.Eq(t, ComponentStopped, admin.State())
+
+		ctrl.RequestStop(nil)
+		test.NoError(t, ctrl.Wait())
+	})
+}
+
+func TestAdminHandler_noErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	AdminHandler(NewController(t.Context())).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/errors", nil))
+	test.Eq(t, http.StatusOK, w.Code)
+	test.Eq(t, "[]\n", w.Body.String())
+}
@@ -22,6 +22,9 @@ func ConstBackoff(delay time.Duration) BackoffFunc {
 // If enabled, Jitter is a random +/- 10% of the computed delay.
 //
 // As a final step, the calculated backoff is clamped to within [minDelay, maxDelay].
+//
+// If the function isn't called again until more than maxDelay after the previous delay was over, whatever it was
+// delaying has been doing fine in the meantime (e.g. a restarted component stayed up), so `N` starts over from 1.
 func ExpBackoff(minDelay, maxDelay time.Duration, exp float64, jitter bool) BackoffFunc {
 	minDelayF := float64(minDelay)
 	maxDelayF := float64(maxDelay)
@@ -34,7 +37,13 @@ func ExpBackoff(minDelay, maxDelay time.Duration, exp float64, jitter bool) Back
 	}
 
 	attempt := 0
+	var delayOver time.Time
 	return func() time.Duration {
+		now := time.Now()
+		if attempt > 0 && now.Sub(delayOver) > maxDelay {
+			attempt = 0
+		}
+
 		delayF := minDelayF * math.Pow(exp, float64(attempt))
 		attempt++
 
@@ -45,6 +54,7 @@ func ExpBackoff(minDelay, maxDelay time.Duration, exp float64, jitter bool) Back
 
 		delayF = min(delayF, maxDelayF)
 		delayF = max(delayF, minDelayF)
+		delayOver = now.Add(time.Duration(delayF))
 		return time.Duration(delayF)
 	}
 }
@@ -2,8 +2,10 @@ package launch
 
 import (
 	"testing"
+	"testing/synctest"
 	"time"
 
+	"github.com/shoenig/test"
 	"github.com/spikesdivzero/launch-control/internal/testutil"
 )
 
@@ -100,6 +102,28 @@ func TestExpBackoff(t *testing.T) {
 		}
 	})
 
+	t.Run("reset once stable", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			bf := ExpBackoff(time.Second, 10*time.Second, 2.0, false)
+			next := func() time.Duration {
+				d := bf()
+				time.Sleep(d)
+				return d
+			}
+			test.Eq(t, time.Second, next())
+			test.Eq(t, 2*time.Second, next())
+
+			// Quickly failing again keeps backing off further.
+			time.Sleep(10 * time.Second)
+			test.Eq(t, 4*time.Second, next())
+
+			// But once whatever it's delaying has been fine for longer than maxDelay, it starts over.
+			time.Sleep(10*time.Second + 1)
+			test.Eq(t, time.Second, next())
+			test.Eq(t, 2*time.Second, next())
+		})
+	})
+
 	t.Run("zero min", func(t *testing.T) {
 		defer testutil.WantPanic(t, "")
 		ExpBackoff(0, time.Second, 2.0, true)
@@ -0,0 +1,227 @@
+// Command launchctl talks to the control socket of a running application (see launch.WithControlSocket).
+//
+// Usage:
+//
+//	launchctl [-socket path] [-timeout duration] <command> [arguments]
+//
+// The socket defaults to $LAUNCHCTL_SOCKET. The commands are:
+//
+//	status [-json]       show the state of the controller and of each component
+//	stop [-reason text]  request a graceful stop
+//	reload               reload the components that support it
+//	dump-stacks          print the stacks of all goroutines
+//	health               exit with 0 only if the application is Alive and ready, e.g. for a Docker HEALTHCHECK
+//
+// launchctl exits with 0 on success, 1 if the command failed (or the application isn't healthy), and 2 if it was
+// used incorrectly.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spikesdivzero/launch-control"
+)
+
+const (
+	exitOK    = 0
+	exitFail  = 1
+	exitUsage = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("launchctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: launchctl [-socket path] [-timeout duration] status|stop|reload|dump-stacks|health")
+		fs.PrintDefaults()
+	}
+	socket := fs.String("socket", os.Getenv("LAUNCHCTL_SOCKET"), "path of the control socket")
+	timeout := fs.Duration("timeout", 10*time.Second, "how long to wait for a response")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 || *socket == "" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	c := client{ctx, *socket}
+
+	cmd, cmdArgs := fs.Arg(0), fs.Args()[1:]
+	var err error
+	switch cmd {
+	case "status":
+		err = c.status(cmdArgs, stdout, stderr)
+	case "stop":
+		err = c.stop(cmdArgs, stderr)
+	case "reload":
+		err = c.simple("reload", cmdArgs, stderr)
+	case "dump-stacks":
+		err = c.dumpStacks(cmdArgs, stdout, stderr)
+	case "health":
+		err = c.health(cmdArgs, stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "launchctl: unknown command %q\n", cmd)
+		fs.Usage()
+		return exitUsage
+	}
+
+	var usageErr usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "launchctl: %v\n", err)
+		return exitFail
+	}
+}
+
+// Returned once the flag package has already reported the problem.
+type usageError struct{ error }
+
+func parseCommandFlags(fs *flag.FlagSet, args []string, stderr io.Writer) error {
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		return usageError{err}
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintf(stderr, "launchctl %s: unexpected arguments %q\n", fs.Name(), fs.Args())
+		return usageError{errors.New("unexpected arguments")}
+	}
+	return nil
+}
+
+type client struct {
+	ctx  context.Context
+	path string
+}
+
+func (c client) do(req launch.ControlRequest) (launch.ControlResponse, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(c.ctx, "unix", c.path)
+	if err != nil {
+		return launch.ControlResponse{}, err
+	}
+	defer conn.Close()
+	if deadline, ok := c.ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return launch.ControlResponse{}, err
+	}
+	var resp launch.ControlResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return launch.ControlResponse{}, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+func (c client) simple(command string, args []string, stderr io.Writer) error {
+	if err := parseCommandFlags(flag.NewFlagSet(command, flag.ContinueOnError), args, stderr); err != nil {
+		return err
+	}
+	_, err := c.do(launch.ControlRequest{Command: command})
+	return err
+}
+
+func (c client) status(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the raw status as JSON")
+	if err := parseCommandFlags(fs, args, stderr); err != nil {
+		return err
+	}
+
+	resp, err := c.do(launch.ControlRequest{Command: "status"})
+	if err != nil {
+		return err
+	}
+	s := resp.Status
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+
+	readiness := "not ready"
+	if s.Ready() {
+		readiness = "ready"
+	}
+	fmt.Fprintf(stdout, "controller: %s (%s)\n\n", s.State, readiness)
+
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATE\tSTARTED\tREADY\tLAST ERROR")
+	for _, cs := range s.Components {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			cs.Name, cs.State, formatTime(cs.StartedAt), formatTime(cs.ReadyAt), cs.LastError)
+	}
+	return tw.Flush()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+func (c client) stop(args []string, stderr io.Writer) error {
+	fs := flag.NewFlagSet("stop", flag.ContinueOnError)
+	reason := fs.String("reason", "", "why the application is being stopped, recorded as the stop reason")
+	if err := parseCommandFlags(fs, args, stderr); err != nil {
+		return err
+	}
+
+	_, err := c.do(launch.ControlRequest{Command: "stop", Reason: *reason})
+	return err
+}
+
+func (c client) dumpStacks(args []string, stdout, stderr io.Writer) error {
+	if err := parseCommandFlags(flag.NewFlagSet("dump-stacks", flag.ContinueOnError), args, stderr); err != nil {
+		return err
+	}
+
+	resp, err := c.do(launch.ControlRequest{Command: "dump-stacks"})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(stdout, resp.Stacks)
+	return err
+}
+
+func (c client) health(args []string, stdout, stderr io.Writer) error {
+	if err := parseCommandFlags(flag.NewFlagSet("health", flag.ContinueOnError), args, stderr); err != nil {
+		return err
+	}
+
+	resp, err := c.do(launch.ControlRequest{Command: "status"})
+	if err != nil {
+		return err
+	}
+	if !resp.Status.Ready() {
+		return fmt.Errorf("not ready (controller is %s)", resp.Status.State)
+	}
+	fmt.Fprintln(stdout, "ok")
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control"
+)
+
+func TestRun(t *testing.T) {
+	// Socket paths are limited to ~100 bytes, which t.TempDir can exceed.
+	dir, err := os.MkdirTemp("", "launchctl")
+	must.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ctl.sock")
+
+	ctrl := launch.NewController(t.Context(), launch.WithControlSocket(path))
+	ctrl.Launch("db", launch.WithStartStop(
+		func(context.Context) error { return nil },
+		func(context.Context) error { return nil }))
+	// A failed Optional component doesn't make the application unhealthy.
+	ctrl.Launch("cache",
+		launch.WithCriticality(launch.Optional),
+		launch.WithStartStop(
+			func(context.Context) error { return nil },
+			func(context.Context) error { return nil }),
+		launch.WithCheckReadyMaxAttempts(1),
+		launch.WithCheckReady(func(context.Context) (bool, error) { return false, errors.New("cold") }))
+
+	launchctl := func(args ...string) (code int, stdout, stderr string) {
+		var outBuf, errBuf bytes.Buffer
+		code = run(append([]string{"-socket", path}, args...), &outBuf, &errBuf)
+		return code, outBuf.String(), errBuf.String()
+	}
+
+	t.Run("usage", func(t *testing.T) {
+		code, _, stderr := launchctl()
+		test.Eq(t, exitUsage, code)
+		test.StrContains(t, stderr, "usage: launchctl")
+
+		code, _, stderr = launchctl("explode")
+		test.Eq(t, exitUsage, code)
+		test.StrContains(t, stderr, `unknown command "explode"`)
+
+		code, _, stderr = launchctl("reload", "now")
+		test.Eq(t, exitUsage, code)
+		test.StrContains(t, stderr, `launchctl reload: unexpected arguments ["now"]`)
+
+		code, _, _ = launchctl("stop", "--bogus")
+		test.Eq(t, exitUsage, code)
+	})
+
+	t.Run("no socket", func(t *testing.T) {
+		var stderr bytes.Buffer
+		code := run([]string{"-socket", filepath.Join(dir, "nope.sock"), "status"}, &bytes.Buffer{}, &stderr)
+		test.Eq(t, exitFail, code)
+		test.StrContains(t, stderr.String(), "no such file or directory")
+	})
+
+	// Startup hasn't finished yet.
+	code, stdout, stderr := launchctl("health")
+	test.Eq(t, exitFail, code)
+	test.Eq(t, "", stdout)
+	test.Eq(t, "launchctl: not ready (controller is Alive)\n", stderr)
+
+	must.NoError(t, ctrl.FinishStartup())
+	waitCh := make(chan error)
+	go func() { waitCh <- ctrl.Wait() }()
+
+	code, stdout, _ = launchctl("health")
+	test.Eq(t, exitOK, code)
+	test.Eq(t, "ok\n", stdout)
+
+	code, stdout, _ = launchctl("status")
+	test.Eq(t, exitOK, code)
+	test.StrContains(t, stdout, "controller: Alive (ready)\n\nNAME   STATE   STARTED")
+	test.StrContains(t, stdout, "\ndb     Ready   ")
+	test.StrContains(t, stdout, "\ncache  Failed  ")
+
+	code, stdout, _ = launchctl("status", "-json")
+	test.Eq(t, exitOK, code)
+	test.StrContains(t, stdout, `"state": "Alive"`)
+
+	code, stdout, _ = launchctl("dump-stacks")
+	test.Eq(t, exitOK, code)
+	test.StrContains(t, stdout, "goroutine ")
+
+	code, _, _ = launchctl("reload")
+	test.Eq(t, exitOK, code)
+
+	code, _, _ = launchctl("stop", "--reason", "disk full")
+	test.Eq(t, exitOK, code)
+	test.EqError(t, <-waitCh, "stop requested via control socket: disk full")
+}
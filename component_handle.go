@@ -0,0 +1,87 @@
+package launch
+
+import (
+	"fmt"
+
+	"github.com/spikesdivzero/launch-control/internal/controller"
+)
+
+// A ComponentState describes where a component is in its lifecycle. See [ComponentHandle.State].
+type ComponentState int
+
+const (
+	// The component is waiting on its dependencies before it can be started.
+	ComponentPending = ComponentState(controller.ComponentPending)
+
+	// The component has been started (or is waiting to be restarted), but isn't ready yet.
+	ComponentStarting = ComponentState(controller.ComponentStarting)
+
+	// The component passed its readiness check, and is running.
+	ComponentReady = ComponentState(controller.ComponentReady)
+
+	// The component is being shut down.
+	ComponentStopping = ComponentState(controller.ComponentStopping)
+
+	// The component was shut down, or was never started because the controller was already shutting down.
+	ComponentStopped = ComponentState(controller.ComponentStopped)
+
+	// The component failed to start, failed to become ready, exited unexpectedly, or one of its dependencies failed.
+	ComponentFailed = ComponentState(controller.ComponentFailed)
+
+	// The component failed to respond to its shutdown, and was abandoned.
+	ComponentAbandoned = ComponentState(controller.ComponentAbandoned)
+)
+
+func (s ComponentState) String() string {
+	return controller.ComponentState(s).String()
+}
+
+// MarshalText encodes the state by its name, e.g. "Ready".
+func (s ComponentState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText is the inverse of [ComponentState.MarshalText].
+func (s *ComponentState) UnmarshalText(text []byte) error {
+	for state := ComponentPending; state <= ComponentAbandoned; state++ {
+		if state.String() == string(text) {
+			*s = state
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown component state %q", text)
+}
+
+// A ComponentHandle is returned by [Controller.Launch] and [Controller.LaunchGroup], and allows checking on a single
+// component after it was launched.
+//
+// The [ComponentStopped], [ComponentFailed], and [ComponentAbandoned] states are final. Once a component reaches
+// one of them, it remains there.
+type ComponentHandle struct {
+	impl *controller.ComponentHandle
+}
+
+// State returns the current state of the component.
+func (h *ComponentHandle) State() ComponentState {
+	return ComponentState(h.impl.State())
+}
+
+// Ready reports whether the component is currently ready.
+func (h *ComponentHandle) Ready() bool {
+	return h.State() == ComponentReady
+}
+
+// Done returns a channel that's closed once the component's `Run` has exited for good (i.e. the component has
+// reached a final state, and won't be restarted). It's also closed for components that were never started, and for
+// components that were abandoned, in which case `Run` may never exit.
+func (h *ComponentHandle) Done() <-chan struct{} {
+	return h.impl.Done()
+}
+
+// Err returns the error that put the component into its final state, if any.
+//
+// For a [ComponentFailed] component, this is the error that caused the failure. For a [ComponentStopped] component,
+// it's the error returned by `Run` as it exited, which is usually nil.
+func (h *ComponentHandle) Err() error {
+	return h.impl.Err()
+}
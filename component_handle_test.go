@@ -0,0 +1,29 @@
+package launch
+
+import (
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/controller"
+)
+
+func TestComponentState_String(t *testing.T) {
+	test.Eq(t, "Pending", ComponentPending.String())
+	test.Eq(t, "Abandoned", ComponentAbandoned.String())
+	test.Eq(t, controller.ComponentFailed.String(), ComponentFailed.String())
+}
+
+func TestComponentState_Text(t *testing.T) {
+	for state := ComponentPending; state <= ComponentAbandoned; state++ {
+		text, err := state.MarshalText()
+		must.NoError(t, err)
+
+		var got ComponentState
+		must.NoError(t, got.UnmarshalText(text))
+		test.Eq(t, state, got)
+	}
+
+	var got ComponentState
+	test.EqError(t, got.UnmarshalText([]byte("Bored")), `unknown component state "Bored"`)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"net"
 	"strings"
 	"time"
 
@@ -47,6 +48,8 @@ type componentBuildState struct {
 	// report them with the call stacks to make it a bit easier for the dev to trace down where it happened.
 	appliedRunCalls        [][2]string // {funcName, stack}
 	appliedCheckReadyCalls [][2]string // the funcName is always the same here, but using the same time lets us share an error
+
+	listenerNames []string
 }
 
 func newComponentBuildState(name string) *componentBuildState {
@@ -90,6 +93,22 @@ func buildComponent(name string, opts ...ComponentOption) (*component.Component,
 		}
 	}
 
+	if len(cbs.listenerNames) > 0 {
+		listeners := map[string]net.Listener{}
+		for _, name := range cbs.listenerNames {
+			ln, err := claimListener(name)
+			if err != nil {
+				return nil, fmt.Errorf("WithListener: %w", err)
+			}
+			listeners[name] = ln
+		}
+
+		run := cbs.c.ImplRun
+		cbs.c.ImplRun = func(ctx context.Context) error {
+			return run(contextWithListeners(ctx, listeners))
+		}
+	}
+
 	return cbs.c, nil
 }
 
@@ -182,6 +201,65 @@ func WithShutdownCompletionTimeout(d time.Duration) ComponentOption {
 	}
 }
 
+// Sets a function to be called as soon as the controller starts shutting down, before any component is shut down.
+//
+// The quiesce functions of all the running components are called in parallel, and the usual shutdown, in reverse
+// launch order, only begins once all of them have returned. This is the place to stop taking on new work: fail the
+// readiness check, deregister from service discovery, stop accepting new connections, etc. Otherwise, a component
+// that's stopped late in the order (e.g. the public HTTP server, launched last) keeps taking on work while the
+// components stopped before it are still shutting down.
+//
+// Errors are recorded in [Controller.AllErrors], but don't hold up the shutdown. ctx carries the [ShutdownReason],
+// and is cancelled once the quiesce timeout passes (30 seconds, unless set via [WithQuiesceTimeout]), or if
+// [WithControllerShutdownTimeout] runs out first, less any time held back via [WithShutdownReserve]. A quiesce
+// function that doesn't return within the async grace period after that is abandoned, with a timeout error
+// recorded, so that it can't hold up the shutdown forever.
+//
+// Quiesce is only called when the whole controller shuts down, not when the component is stopped on its own (e.g.
+// via [Controller.StopComponent]).
+func WithQuiesce(fn func(context.Context) error) ComponentOption {
+	if fn == nil {
+		panic(optionNilArgError{"WithQuiesce", "fn"})
+	}
+
+	return func(cbs *componentBuildState) {
+		cbs.c.ImplQuiesce = fn
+	}
+}
+
+// Sets how long the function given to [WithQuiesce] may take. If d is zero or negative, it defaults to [NoTimeout],
+// leaving only [WithControllerShutdownTimeout] to bound it.
+//
+// If not provided, it defaults to 30 seconds. Unlike the other timeouts, quiesce is bounded by default, as it runs
+// before anything is shut down, and so would otherwise hold up the whole shutdown.
+func WithQuiesceTimeout(d time.Duration) ComponentOption {
+	if d <= 0 {
+		d = NoTimeout
+	}
+
+	return func(cbs *componentBuildState) {
+		cbs.c.ShutdownOptions.QuiesceTimeout = d
+	}
+}
+
+// Holds back d of the controller's shutdown budget (see [WithControllerShutdownTimeout]) for this component, so that
+// the components stopped before it can't use it up. Meant for components that must get their chance to shut down,
+// even when others are slow to, such as log and metrics flushers.
+//
+// Until the component starts shutting down, the reserve is taken away from the time given to the others, so it's
+// best kept small. It has no effect without [WithControllerShutdownTimeout].
+//
+// Both zero and negative durations mean no reserve, which is the default.
+func WithShutdownReserve(d time.Duration) ComponentOption {
+	if d < 0 {
+		d = 0
+	}
+
+	return func(cbs *componentBuildState) {
+		cbs.c.ShutdownOptions.Reserve = d
+	}
+}
+
 // Wraps the provided `Start` and `Stop` functions, making them compatible with the controllers Run-Shutdown model.
 //
 // Both `Start` and `Stop` are expected to return once their respective step is completed.
@@ -292,3 +370,205 @@ func WithCheckReadyMaxAttempts(n int) ComponentOption {
 		cbs.c.CheckReadyOptions.MaxAttempts = n
 	}
 }
+
+// Declares the components that this component depends on, by name.
+//
+// By default, a component depends on every component launched before it, so it's shut down before all of them. Once
+// this option is provided, the component depends only on the named components instead. It's started once they're
+// all ready, and shut down before any of them, but may otherwise start and stop in parallel with unrelated
+// components. Calling this with no names declares that the component has no dependencies at all.
+//
+// Each name must refer to a component that was previously launched, or to another member of the same
+// [Controller.LaunchGroup]. Unknown names and dependency cycles are reported in the same way as other build errors.
+//
+// Multiple calls are cumulative.
+func WithDependsOn(names ...string) ComponentOption {
+	for _, name := range names {
+		if name == "" {
+			panic("WithDependsOn: names must not be empty")
+		}
+	}
+
+	return func(cbs *componentBuildState) {
+		if cbs.c.DependsOn == nil {
+			cbs.c.DependsOn = []string{}
+		}
+		cbs.c.DependsOn = append(cbs.c.DependsOn, names...)
+	}
+}
+
+// A RestartPolicy decides whether a component is restarted after its `Run` exits. See [WithRestartPolicy].
+type RestartPolicy int
+
+const (
+	// Never restart the component. Any exit causes the controller to shut down. This is the default.
+	RestartNever = RestartPolicy(component.RestartNever)
+
+	// Restart the component only if `Run` returned an error.
+	RestartOnFailure = RestartPolicy(component.RestartOnFailure)
+
+	// Restart the component whenever `Run` returns, even without an error.
+	RestartAlways = RestartPolicy(component.RestartAlways)
+)
+
+// Allows the controller to restart the component when its `Run` exits, instead of shutting down the application.
+//
+// A restart calls `Start` (or `Run`) again, followed by `CheckReady` as it would during a [Controller.Launch]. If
+// that fails, it's handled in the same way as a failed launch. Each restart is delayed by [WithRestartBackoff], and
+// is recorded in [Controller.AllErrors] as a component error with the "restart" stage.
+//
+// At most maxRestarts restarts are permitted within any trailing window of time. Once that budget runs out, the
+// next exit is handled as if there were no restart policy, and the controller shuts down.
+//
+// Both zero and negative maxRestarts are replaced with [math.MaxInt], and both zero and negative window
+// durations are replaced with [NoTimeout].
+func WithRestartPolicy(policy RestartPolicy, maxRestarts int, window time.Duration) ComponentOption {
+	if policy < RestartNever || policy > RestartAlways {
+		panic(fmt.Sprintf("WithRestartPolicy: unknown policy %d", policy))
+	}
+	if maxRestarts <= 0 {
+		maxRestarts = math.MaxInt
+	}
+	if window <= 0 {
+		window = NoTimeout
+	}
+
+	return func(cbs *componentBuildState) {
+		cbs.c.RestartOptions.Policy = component.RestartPolicy(policy)
+		cbs.c.RestartOptions.MaxRestarts = maxRestarts
+		cbs.c.RestartOptions.Window = window
+	}
+}
+
+// Defines a function that returns how long to wait before each restart allowed by [WithRestartPolicy].
+//
+// If not provided, it defaults to a function that always returns a 1 second delay. An [ExpBackoff] starts over once
+// the component has stayed up for longer than its maxDelay.
+func WithRestartBackoff(
+	backoff BackoffFunc,
+) ComponentOption {
+	if backoff == nil {
+		panic(optionNilArgError{"WithRestartBackoff", "backoff"})
+	}
+
+	return func(cbs *componentBuildState) {
+		cbs.c.RestartOptions.Backoff = backoff
+	}
+}
+
+// A Criticality decides how the controller reacts when a component fails. See [WithCriticality].
+type Criticality int
+
+const (
+	// Any failure of the component causes the controller to shut down. This is the default.
+	Critical = Criticality(component.Critical)
+
+	// Failures of the component are recorded, but the rest of the application keeps running.
+	Optional = Criticality(component.Optional)
+)
+
+func (c Criticality) String() string {
+	switch c {
+	case Critical:
+		return "Critical"
+	case Optional:
+		return "Optional"
+	default:
+		return fmt.Sprintf("Criticality(%d)", int(c))
+	}
+}
+
+// MarshalText encodes the criticality by its name, e.g. "Optional".
+func (c Criticality) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText is the inverse of [Criticality.MarshalText].
+func (c *Criticality) UnmarshalText(text []byte) error {
+	for crit := Critical; crit <= Optional; crit++ {
+		if crit.String() == string(text) {
+			*c = crit
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown criticality %q", text)
+}
+
+// Sets how the controller reacts when the component fails.
+//
+// When an [Optional] component fails to start, fails to become ready, or its `Run` exits (after any restarts allowed
+// by [WithRestartPolicy]), the failure is still recorded in [Controller.AllErrors], but the controller is not asked
+// to stop. The failed component is shut down on its own, and is skipped when the controller later shuts down.
+//
+// Components that explicitly depend on a failed component (see [WithDependsOn]) are never started. Their launch
+// fails with a dependency error, which is in turn handled according to their own criticality. Components launched
+// later without [WithDependsOn] don't depend on a component that has already failed, and are started as usual.
+//
+// Errors from an Optional component are never returned by [Controller.Err] or [Controller.Wait].
+func WithCriticality(c Criticality) ComponentOption {
+	if c < Critical || c > Optional {
+		panic(fmt.Sprintf("WithCriticality: unknown criticality %d", c))
+	}
+
+	return func(cbs *componentBuildState) {
+		cbs.c.Criticality = component.Criticality(c)
+	}
+}
+
+// Allows the component to take part in [Controller.Reload], e.g. to pick up a new configuration.
+//
+// A reload happens in two phases. First, `prepare` is called on every reloadable component, which should do anything
+// that may fail (reading and validating the new config, opening new connections, etc) without yet putting it into
+// use. Only once every component has prepared successfully is `commit` called on each of them, which should switch
+// over to what was prepared. If any component fails to prepare, the ones that already did are aborted instead (see
+// [WithReloadAbort]), and nothing is committed.
+//
+// A `commit` that fails leaves the component in an unknown state, so it's handled in the same way as the
+// component's `Run` exiting with that error.
+//
+// Only components that are ready at the time of the reload take part.
+func WithReload(prepare, commit func(context.Context) error) ComponentOption {
+	if prepare == nil {
+		panic(optionNilArgError{"WithReload", "prepare"})
+	}
+	if commit == nil {
+		panic(optionNilArgError{"WithReload", "commit"})
+	}
+
+	return func(cbs *componentBuildState) {
+		cbs.c.ReloadOptions.Prepare = prepare
+		cbs.c.ReloadOptions.Commit = commit
+	}
+}
+
+// Defines a function that discards whatever was done by the `prepare` function given to [WithReload], for when
+// another component failed to prepare.
+//
+// If not provided, nothing is called, and the component is expected to discard a prepared reload on its own the
+// next time `prepare` is called.
+func WithReloadAbort(abort func(context.Context) error) ComponentOption {
+	if abort == nil {
+		panic(optionNilArgError{"WithReloadAbort", "abort"})
+	}
+
+	return func(cbs *componentBuildState) {
+		cbs.c.ReloadOptions.Abort = abort
+	}
+}
+
+// Hands the named listener, inherited from systemd's socket activation (see [Listeners]) or opened via [Listen], to
+// the component. The component's `Run` (or `Start`) can then get it via [ListenerFromContext].
+//
+// Claiming a listener keeps it from being closed once startup finishes. The same listener is handed to every run
+// of the component, including restarts, so a component that's expected to be restarted shouldn't close it.
+//
+// It's a build error if there's no listener by that name. Multiple calls are cumulative.
+func WithListener(name string) ComponentOption {
+	if name == "" {
+		panic("WithListener: name must not be empty")
+	}
+
+	return func(cbs *componentBuildState) {
+		cbs.listenerNames = append(cbs.listenerNames, name)
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/shoenig/test"
 	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/component"
 	"github.com/spikesdivzero/launch-control/internal/testutil"
 )
 
@@ -231,6 +232,56 @@ func TestWithShutdownCompletionTimeout(t *testing.T) {
 	}
 }
 
+func TestWithQuiesce(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		cbs := newComponentBuildState("test")
+		err := errors.New("quiesce")
+		WithQuiesce(func(context.Context) error { return err })(cbs)
+		test.ErrorIs(t, cbs.c.ImplQuiesce(t.Context()), err)
+	})
+
+	t.Run("nil fn", func(t *testing.T) {
+		defer testutil.WantPanic(t, optionNilArgError{"WithQuiesce", "fn"}.Error())
+		WithQuiesce(nil)
+	})
+}
+
+func TestWithQuiesceTimeout(t *testing.T) {
+	test.Eq(t, 30*time.Second, newComponentBuildState("test").c.ShutdownOptions.QuiesceTimeout)
+
+	tests := []struct {
+		argD, wantD time.Duration
+	}{
+		{-12, NoTimeout},
+		{0, NoTimeout},
+		{2 * time.Second, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.argD.String(), func(t *testing.T) {
+			cbs := newComponentBuildState("test")
+			WithQuiesceTimeout(tt.argD)(cbs)
+			test.Eq(t, tt.wantD, cbs.c.ShutdownOptions.QuiesceTimeout)
+		})
+	}
+}
+
+func TestWithShutdownReserve(t *testing.T) {
+	tests := []struct {
+		argD, wantD time.Duration
+	}{
+		{-12, 0},
+		{0, 0},
+		{2 * time.Second, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.argD.String(), func(t *testing.T) {
+			cbs := newComponentBuildState("test")
+			WithShutdownReserve(tt.argD)(cbs)
+			test.Eq(t, tt.wantD, cbs.c.ShutdownOptions.Reserve)
+		})
+	}
+}
+
 func TestWithStartStop(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
 		cbs := newComponentBuildState("test")
@@ -386,3 +437,152 @@ func TestWithCheckReadyMaxAttempts(t *testing.T) {
 		})
 	}
 }
+
+func TestWithDependsOn(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		cbs := newComponentBuildState("test")
+		test.Nil(t, cbs.c.DependsOn)
+	})
+
+	t.Run("no names", func(t *testing.T) {
+		cbs := newComponentBuildState("test")
+		WithDependsOn()(cbs)
+		test.NotNil(t, cbs.c.DependsOn) // explicitly no dependencies
+		test.SliceEmpty(t, cbs.c.DependsOn)
+	})
+
+	t.Run("cumulative", func(t *testing.T) {
+		cbs := newComponentBuildState("test")
+		WithDependsOn("alfa", "bravo")(cbs)
+		WithDependsOn("charlie")(cbs)
+		test.Eq(t, []string{"alfa", "bravo", "charlie"}, cbs.c.DependsOn)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		defer testutil.WantPanic(t, "WithDependsOn: names must not be empty")
+		WithDependsOn("alfa", "")
+	})
+}
+
+func TestWithRestartPolicy(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		cbs := newComponentBuildState("test")
+		test.Eq(t, component.RestartNever, cbs.c.RestartOptions.Policy)
+		test.Eq(t, math.MaxInt, cbs.c.RestartOptions.MaxRestarts)
+		test.Eq(t, NoTimeout, cbs.c.RestartOptions.Window)
+		test.Eq(t, time.Second, cbs.c.RestartOptions.Backoff())
+	})
+
+	tests := []struct {
+		name         string
+		policy       RestartPolicy
+		maxRestarts  int
+		window       time.Duration
+		wantPolicy   component.RestartPolicy
+		wantRestarts int
+		wantWindow   time.Duration
+	}{
+		{"on failure", RestartOnFailure, 3, time.Minute, component.RestartOnFailure, 3, time.Minute},
+		{"always", RestartAlways, 5, time.Hour, component.RestartAlways, 5, time.Hour},
+		{"never", RestartNever, 1, time.Second, component.RestartNever, 1, time.Second},
+		{"zeros", RestartAlways, 0, 0, component.RestartAlways, math.MaxInt, NoTimeout},
+		{"negatives", RestartAlways, -1, -1, component.RestartAlways, math.MaxInt, NoTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cbs := newComponentBuildState("test")
+			WithRestartPolicy(tt.policy, tt.maxRestarts, tt.window)(cbs)
+			test.Eq(t, tt.wantPolicy, cbs.c.RestartOptions.Policy)
+			test.Eq(t, tt.wantRestarts, cbs.c.RestartOptions.MaxRestarts)
+			test.Eq(t, tt.wantWindow, cbs.c.RestartOptions.Window)
+		})
+	}
+
+	t.Run("unknown policy", func(t *testing.T) {
+		defer testutil.WantPanic(t, "WithRestartPolicy: unknown policy 7")
+		WithRestartPolicy(RestartPolicy(7), 1, time.Second)
+	})
+}
+
+func TestWithRestartBackoff(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		cbs := newComponentBuildState("test")
+		WithRestartBackoff(ConstBackoff(12 * time.Second))(cbs)
+		test.Eq(t, 12*time.Second, cbs.c.RestartOptions.Backoff())
+	})
+
+	t.Run("nil backoff", func(t *testing.T) {
+		defer testutil.WantPanic(t, optionNilArgError{"WithRestartBackoff", "backoff"}.Error())
+		WithRestartBackoff(nil)
+	})
+}
+
+func TestWithCriticality(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		cbs := newComponentBuildState("test")
+		test.Eq(t, component.Critical, cbs.c.Criticality)
+	})
+
+	t.Run("optional", func(t *testing.T) {
+		cbs := newComponentBuildState("test")
+		WithCriticality(Optional)(cbs)
+		test.Eq(t, component.Optional, cbs.c.Criticality)
+	})
+
+	t.Run("critical", func(t *testing.T) {
+		cbs := newComponentBuildState("test")
+		WithCriticality(Optional)(cbs)
+		WithCriticality(Critical)(cbs)
+		test.Eq(t, component.Critical, cbs.c.Criticality)
+	})
+
+	t.Run("unknown criticality", func(t *testing.T) {
+		defer testutil.WantPanic(t, "WithCriticality: unknown criticality 7")
+		WithCriticality(Criticality(7))
+	})
+}
+
+func TestWithReload(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		cbs := newComponentBuildState("test")
+		test.Nil(t, cbs.c.ReloadOptions.Prepare)
+		test.Nil(t, cbs.c.ReloadOptions.Commit)
+	})
+
+	t.Run("happy", func(t *testing.T) {
+		var calls []string
+		cbs := newComponentBuildState("test")
+		WithReload(
+			func(context.Context) error { calls = append(calls, "prepare"); return nil },
+			func(context.Context) error { calls = append(calls, "commit"); return nil },
+		)(cbs)
+
+		test.NoError(t, cbs.c.ReloadOptions.Prepare(t.Context()))
+		test.NoError(t, cbs.c.ReloadOptions.Commit(t.Context()))
+		test.Eq(t, []string{"prepare", "commit"}, calls)
+	})
+
+	t.Run("nil prepare", func(t *testing.T) {
+		defer testutil.WantPanic(t, optionNilArgError{"WithReload", "prepare"}.Error())
+		WithReload(nil, func(context.Context) error { return nil })
+	})
+
+	t.Run("nil commit", func(t *testing.T) {
+		defer testutil.WantPanic(t, optionNilArgError{"WithReload", "commit"}.Error())
+		WithReload(func(context.Context) error { return nil }, nil)
+	})
+}
+
+func TestWithReloadAbort(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		testErr := errors.New("aborted")
+		cbs := newComponentBuildState("test")
+		WithReloadAbort(func(context.Context) error { return testErr })(cbs)
+		test.ErrorIs(t, cbs.c.ReloadOptions.Abort(t.Context()), testErr)
+	})
+
+	t.Run("nil abort", func(t *testing.T) {
+		defer testutil.WantPanic(t, optionNilArgError{"WithReloadAbort", "abort"}.Error())
+		WithReloadAbort(nil)
+	})
+}
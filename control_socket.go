@@ -0,0 +1,172 @@
+package launch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"time"
+)
+
+// The longest a control socket client may take to send its request, or to read the response. It also bounds a
+// reload, so that a stuck component can't tie up the connection forever.
+const controlSocketTimeout = 30 * time.Second
+
+// A ControlRequest is sent by a client of the control socket (see [WithControlSocket]), as a single JSON object.
+type ControlRequest struct {
+	// One of "status", "stop", "reload", or "dump-stacks".
+	Command string `json:"command"`
+
+	// For "stop", an optional reason, which is included in the [ControlStopError].
+	Reason string `json:"reason,omitempty"`
+}
+
+// A ControlResponse is the control socket's reply to a [ControlRequest], as a single JSON object.
+type ControlResponse struct {
+	// Set if the command failed.
+	Error string `json:"error,omitempty"`
+
+	// Set in response to "status".
+	Status *Status `json:"status,omitempty"`
+
+	// Set in response to "dump-stacks", in the same format as an unrecovered panic.
+	Stacks string `json:"stacks,omitempty"`
+}
+
+// A ControlStopError is the reason passed to [Controller.RequestStop] when a stop is requested via the control
+// socket (see [WithControlSocket]).
+type ControlStopError struct {
+	// As given by the client, which may be empty.
+	Reason string
+}
+
+func (e ControlStopError) Error() string {
+	if e.Reason == "" {
+		return "stop requested via control socket"
+	}
+	return "stop requested via control socket: " + e.Reason
+}
+
+// Serves the control socket until the controller is dead. Failing to listen is logged, rather than fatal, as the
+// application can run just as well without it.
+func serveControlSocket(ctrl Controller, path string) {
+	ln, err := listenControlSocket(path)
+	if err != nil {
+		ctrl.impl.Log.Error("failed to listen on the control socket", "path", path, "error", err)
+		return
+	}
+
+	go func() {
+		_ = ctrl.impl.Wait()
+		ln.Close()
+		_ = os.Remove(path)
+	}()
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			} else if err != nil {
+				ctrl.impl.Log.Error("control socket failed to accept a connection", "path", path, "error", err)
+				return
+			}
+			go handleControlConn(ctrl, conn)
+		}
+	}()
+}
+
+func listenControlSocket(path string) (net.Listener, error) {
+	// Clean up after a previous run that didn't exit cleanly, but don't steal the socket from one that's still
+	// running, or clobber something that isn't a socket.
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists, and isn't a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Anyone who can connect can stop the application, so limit it to the same user. The socket is created in a
+	// private directory, and only moved into place once that's done, so that nobody else can connect in between.
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".ctl")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmpPath := filepath.Join(dir, "s")
+	ln, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false) // it won't be at tmpPath, so it's up to the caller to remove it
+	if err := os.Chmod(tmpPath, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// Each connection carries a single request, and its response.
+func handleControlConn(ctrl Controller, conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(controlSocketTimeout))
+
+	var req ControlRequest
+	var resp ControlResponse
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		resp.Error = fmt.Sprintf("invalid request: %v", err)
+	} else {
+		resp = handleControlRequest(ctrl, req)
+	}
+
+	// A reload can take a while, so it mustn't eat into the time for sending the response.
+	_ = conn.SetDeadline(time.Now().Add(controlSocketTimeout))
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+func handleControlRequest(ctrl Controller, req ControlRequest) ControlResponse {
+	ctrl.impl.Log.Debug("control socket request received", "command", req.Command)
+
+	var resp ControlResponse
+	switch req.Command {
+	case "status":
+		status := ctrl.Status()
+		resp.Status = &status
+
+	case "stop":
+		ctrl.RequestStop(ControlStopError{req.Reason})
+
+	case "reload":
+		ctx, cancel := context.WithTimeout(context.Background(), controlSocketTimeout)
+		defer cancel()
+		if err := ctrl.Reload(ctx); err != nil {
+			resp.Error = err.Error()
+		}
+
+	case "dump-stacks":
+		var buf bytes.Buffer
+		_ = pprof.Lookup("goroutine").WriteTo(&buf, 2)
+		resp.Stacks = buf.String()
+
+	default:
+		resp.Error = fmt.Sprintf("unknown command %q", req.Command)
+	}
+	return resp
+}
@@ -0,0 +1,184 @@
+package launch
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/controller"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+// Returns a path for a control socket. Socket paths are limited to ~100 bytes, which t.TempDir can exceed.
+func controlSocketPath(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "ctl")
+	must.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "ctl.sock")
+}
+
+func TestWithControlSocket(t *testing.T) {
+	c := controller.New(t.Context())
+	WithControlSocket("/run/app.sock")(c)
+	test.Eq(t, "/run/app.sock", c.ControlSocketPath)
+
+	t.Run("panics on empty path", func(t *testing.T) {
+		defer testutil.WantPanic(t, "WithControlSocket: path must not be empty")
+		WithControlSocket("")
+	})
+}
+
+func TestControlSocket(t *testing.T) {
+	path := controlSocketPath(t)
+	ctrl := NewController(t.Context(), WithControlSocket(path))
+
+	send := func(t *testing.T, req any) ControlResponse {
+		t.Helper()
+		conn, err := net.Dial("unix", path)
+		must.NoError(t, err)
+		defer conn.Close()
+		must.NoError(t, json.NewEncoder(conn).Encode(req))
+		var resp ControlResponse
+		must.NoError(t, json.NewDecoder(conn).Decode(&resp))
+		return resp
+	}
+
+	fi, err := os.Stat(path)
+	must.NoError(t, err)
+	test.Eq(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	ctrl.Launch("test", WithStartStop(
+		func(context.Context) error { return nil },
+		func(context.Context) error { return nil }))
+
+	t.Run("status", func(t *testing.T) {
+		resp := send(t, ControlRequest{Command: "status"})
+		test.Eq(t, "", resp.Error)
+		must.NotNil(t, resp.Status)
+		test.Eq(t, ctrl.Status(), *resp.Status)
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		resp := send(t, ControlRequest{Command: "reload"})
+		test.Eq(t, ControlResponse{}, resp)
+	})
+
+	t.Run("dump-stacks", func(t *testing.T) {
+		resp := send(t, ControlRequest{Command: "dump-stacks"})
+		test.StrContains(t, resp.Stacks, "goroutine ")
+		test.StrContains(t, resp.Stacks, "handleControlRequest")
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		resp := send(t, ControlRequest{Command: "explode"})
+		test.Eq(t, `unknown command "explode"`, resp.Error)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		resp := send(t, "status")
+		test.StrContains(t, resp.Error, "invalid request: ")
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		resp := send(t, ControlRequest{Command: "stop", Reason: "disk full"})
+		test.Eq(t, ControlResponse{}, resp)
+		test.EqError(t, ctrl.Wait(), "stop requested via control socket: disk full")
+		test.ErrorIs(t, ctrl.Wait(), error(ControlStopError{"disk full"}))
+	})
+
+	// Once the controller is dead, the socket goes away.
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		must.True(t, time.Now().Before(deadline))
+	}
+}
+
+func Test_handleControlRequest_reloadTimeout(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := NewController(t.Context())
+		ctrl.Launch("stuck",
+			WithStartStop(
+				func(context.Context) error { return nil },
+				func(context.Context) error { return nil }),
+			WithReload(
+				func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() },
+				func(context.Context) error { return nil }))
+		go ctrl.Wait()
+		synctest.Wait()
+
+		start := time.Now()
+		resp := handleControlRequest(ctrl, ControlRequest{Command: "reload"})
+		test.Eq(t, controlSocketTimeout, time.Since(start))
+		test.StrContains(t, resp.Error, context.DeadlineExceeded.Error())
+
+		ctrl.RequestStop(nil)
+		ctrl.Wait()
+	})
+}
+
+func Test_listenControlSocket(t *testing.T) {
+	t.Run("moved into place", func(t *testing.T) {
+		path := controlSocketPath(t)
+		ln, err := listenControlSocket(path)
+		must.NoError(t, err)
+		defer ln.Close()
+
+		fi, err := os.Stat(path)
+		must.NoError(t, err)
+		test.Eq(t, os.FileMode(0o600), fi.Mode().Perm())
+
+		// Nothing's left of the private directory it was created in.
+		entries, err := os.ReadDir(filepath.Dir(path))
+		must.NoError(t, err)
+		must.Len(t, 1, entries)
+		test.Eq(t, filepath.Base(path), entries[0].Name())
+
+		conn, err := net.Dial("unix", path)
+		must.NoError(t, err)
+		conn.Close()
+	})
+
+	t.Run("replaces a stale socket", func(t *testing.T) {
+		path := controlSocketPath(t)
+		ln, err := net.Listen("unix", path)
+		must.NoError(t, err)
+		ln.(*net.UnixListener).SetUnlinkOnClose(false)
+		ln.Close()
+
+		ln, err = listenControlSocket(path)
+		must.NoError(t, err)
+		ln.Close()
+	})
+
+	t.Run("in use", func(t *testing.T) {
+		path := controlSocketPath(t)
+		ln, err := net.Listen("unix", path)
+		must.NoError(t, err)
+		defer ln.Close()
+
+		_, err = listenControlSocket(path)
+		test.EqError(t, err, path+" is in use by another process")
+	})
+
+	t.Run("not a socket", func(t *testing.T) {
+		path := controlSocketPath(t)
+		must.NoError(t, os.WriteFile(path, nil, 0o600))
+
+		_, err := listenControlSocket(path)
+		test.EqError(t, err, path+" exists, and isn't a socket")
+	})
+}
+
+func TestControlStopError(t *testing.T) {
+	test.EqError(t, ControlStopError{}, "stop requested via control socket")
+	test.EqError(t, ControlStopError{"disk full"}, "stop requested via control socket: disk full")
+}
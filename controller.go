@@ -1,14 +1,19 @@
 // Package launch provides a way to launch and monitor components within an application.
 //
 // If any component exits, or if [RequestStop] is called, then the application shuts down so that it can be replaced
-// by another instance. (App instance replacement is assumed to be provided externally -- e.g. k8s, systemd, etc.)
+// by another instance. Components may opt in to being restarted in place instead, via [WithRestartPolicy], or to
+// being treated as non-essential, via [WithCriticality]. (App instance replacement is assumed to be provided
+// externally -- e.g. k8s, systemd, etc.)
 //
-// Shutdown order is the reverse of the [Launch] order, as one would commonly expect.
+// Shutdown order is the reverse of the [Launch] order, as one would commonly expect. Independent components can be
+// started (and later stopped) in parallel via [LaunchGroup], and [WithDependsOn] can be used to declare the exact
+// dependencies between components instead of relying on launch order.
 package launch
 
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/spikesdivzero/launch-control/internal/controller"
 )
@@ -22,11 +27,24 @@ type Controller struct {
 	impl *controller.Controller
 }
 
+// NewController creates a new controller, configured by the given options.
+//
+// Cancelling ctx stops the controller, the same as calling [Controller.RequestStop] with the context's cause as the
+// reason. This makes it easy to use with e.g. [os/signal.NotifyContext]. Otherwise, the controller only keeps ctx's
+// values: the contexts passed to the components are detached from its cancellation, so that the graceful shutdown
+// isn't cut short by the very thing that triggered it, and is instead bounded by the components' own timeouts.
 func NewController(ctx context.Context, opts ...ControllerOption) Controller {
 	c := Controller{impl: controller.New(ctx)}
 	for _, opt := range opts {
 		opt(c.impl)
 	}
+	c.impl.NewShutdownCause = func(r controller.ShutdownReason) error { return newShutdownReason(r) }
+	c.impl.OnStartupFinished = c.onStartupFinished
+	c.impl.WatchParent()
+	c.impl.ListenForSignals(func(sig os.Signal) error { return SignalError{sig} })
+	if c.impl.ControlSocketPath != "" {
+		serveControlSocket(c, c.impl.ControlSocketPath)
+	}
 	return c
 }
 
@@ -36,29 +54,168 @@ func NewController(ctx context.Context, opts ...ControllerOption) Controller {
 // Required options: Nearly every option is, as the name suggests, optional. However you must provide exactly
 // one of [WithRun] or [WithStartStop] as one of the options, as this defines how the component should execute.
 //
-// If a Launch request comes in after the controller has started shutting down, the request will be silently
-// discarded.
-func (c *Controller) Launch(name string, opts ...ComponentOption) {
+// Component names must be unique within the controller, and any names provided to [WithDependsOn] must refer to
+// components that were previously launched.
+//
+// The returned handle can be used to check on the component later. If a Launch request comes in after the controller
+// has started shutting down, the request will be silently discarded, and the handle reports the component as
+// [ComponentStopped].
+func (c *Controller) Launch(name string, opts ...ComponentOption) *ComponentHandle {
+	return c.LaunchGroup(Member(name, opts...))[0]
+}
+
+// A GroupMember describes a single component within a [Controller.LaunchGroup] call. Use [Member] to create one.
+type GroupMember struct {
+	name string
+	opts []ComponentOption
+}
+
+// Member pairs a component name with its options, for use with [Controller.LaunchGroup].
+func Member(name string, opts ...ComponentOption) GroupMember {
+	return GroupMember{name, opts}
+}
+
+// LaunchGroup builds a component for each member, then launches all of them concurrently inside of the controller.
+// This blocks until every member's launch has finished (regardless of success or failure).
+//
+// Unless [WithDependsOn] says otherwise, members of a group are expected to be independent of one another. During
+// shutdown, the group is stopped at the point in the order where it was launched, with all of its members being
+// stopped in parallel.
+//
+// Members using [WithDependsOn] may depend on each other, in which case each member is started as soon as its
+// dependencies are ready. Dependency cycles within the group are treated as a build error.
+//
+// If any member fails to start or become ready, the entire group is considered to be a failed launch. The
+// controller then begins shutting down, which includes stopping the members that did start. The exception is
+// members marked as [Optional] via [WithCriticality], whose failure is recorded but otherwise contained.
+//
+// The same build requirements and discard behavior as [Launch] apply to each member. The returned handles are in
+// the same order as the members.
+func (c *Controller) LaunchGroup(members ...GroupMember) []*ComponentHandle {
+	group := make([]controller.GroupMember, 0, len(members))
+	for _, m := range members {
+		group = append(group, buildGroupMember(m.name, m.opts...))
+	}
+	impls, err := c.impl.LaunchGroup(group)
+	if err != nil {
+		panic(fmt.Sprintf("component build failed: %v", err))
+	}
+
+	handles := make([]*ComponentHandle, 0, len(impls))
+	for _, impl := range impls {
+		handles = append(handles, &ComponentHandle{impl})
+	}
+	return handles
+}
+
+// FinishStartup tells the controller that the application has launched everything it needs to, typically right after
+// the last call to [Launch], ending startup (see [Status.StartupFinished]). Until it's called, the controller isn't
+// considered ready, however many of its components are.
+//
+// Any listeners (see [Listeners] and [Listen]) that weren't claimed via [WithListener] are closed, and if this process
+// was started by [Controller.Upgrade], the old process is told that the upgrade is ready, all before FinishStartup
+// returns. Calling it again has no effect. An error is returned if the controller isn't running, i.e. nothing has
+// been launched yet, or it's already shutting down.
+func (c *Controller) FinishStartup() error {
+	return c.impl.FinishStartup()
+}
+
+func buildGroupMember(name string, opts ...ComponentOption) controller.GroupMember {
 	comp, err := buildComponent(name, opts...)
 	if err != nil {
 		panic(fmt.Sprintf("component build failed: %v", err))
 	}
-	c.impl.Launch(name, comp)
+	return controller.GroupMember{
+		Name:            name,
+		Comp:            comp,
+		DependsOn:       comp.DependsOn,
+		Restart:         comp.RestartOptions,
+		Criticality:     comp.Criticality,
+		Reload:          comp.ReloadOptions,
+		ShutdownReserve: comp.ShutdownOptions.Reserve,
+		Quiesce:         comp.ImplQuiesce,
+		QuiesceTimeout:  comp.ShutdownOptions.QuiesceTimeout,
+	}
+}
+
+// Replace builds a new component from the provided name and options, and hot-swaps it in for the running component
+// of the same name. This blocks until the swap has finished (regardless of success or failure).
+//
+// The new component is started while the old one is still running. Only once it's ready is the old component shut
+// down, with the new one taking over its place in the shutdown order and as a dependency of other components. Any
+// [WithDependsOn] option is ignored, as the new component inherits the old one's dependencies.
+//
+// If the new component fails to start or become ready, it's shut down, the old one keeps running, and the error is
+// returned. An error is also returned if there's no component by that name, or if the controller isn't running.
+//
+// Any [ComponentHandle] from the original launch continues to refer to the old component, and so reports it as
+// stopped once the swap has finished.
+//
+// The same build requirements as [Launch] apply.
+func (c *Controller) Replace(name string, opts ...ComponentOption) error {
+	return c.impl.Replace(buildGroupMember(name, opts...))
+}
+
+// StopComponent shuts down a single running component, leaving the rest of the application running. This blocks
+// until the component has been shut down, using the same escalation as during a normal controller shutdown, with
+// ctx bounding how long the graceful stages may take. If [WithControllerShutdownTimeout] is set, a single component's
+// shutdown doesn't get any longer than that either.
+//
+// The component's exit is expected, and so it's neither treated as a failure nor restarted. The component is removed
+// from the controller entirely as soon as it starts stopping, and its name may be reused by a later [Launch].
+// Components that depended on it keep running, and are ordered during shutdown as if they'd depended on its
+// dependencies. The controller carries on with other requests while the component is stopping, and if the
+// controller starts shutting down in the meantime, the component's dependencies are only stopped once it has been.
+//
+// An error is returned if the component isn't known, if the controller isn't running (i.e. nothing has been
+// launched yet, or it's already shutting down), or if the component's shutdown failed.
+func (c *Controller) StopComponent(ctx context.Context, name string) error {
+	return c.impl.StopComponent(ctx, name)
+}
+
+// Reload reloads every ready component that was launched with [WithReload], blocking until the reload has finished.
+//
+// The components are prepared one at a time, in launch order, and only if all of them succeed are they committed,
+// again in launch order. Otherwise, the components that were already prepared are aborted, and the error from the
+// failed `prepare` is returned, so that the application is never left half-reloaded. Errors from any failed
+// `commit` are returned as well, after the failure has been handled.
+//
+// Reloads never overlap with each other, with launches, or with the controller shutting down. An error is
+// returned if the controller isn't running. ctx is passed to each of the reload functions, and also bounds how long
+// to wait for an ongoing launch to finish. Each of the reload functions is also bounded by [WithReloadTimeout], and is
+// given up on if a stop is requested while it's running.
+func (c *Controller) Reload(ctx context.Context) error {
+	return c.impl.Reload(ctx)
 }
 
 // RequestStop signals to the controller that it's time to exit, with an optional error explaining why.
 //
-// It's safe to call as multiple times. Only the first non-nil error is recorded.
+// It's safe to call as multiple times. Only the first non-nil error is recorded, and only the first call is used for
+// the [ShutdownReason].
 func (c *Controller) RequestStop(reason error) {
 	c.impl.RequestStop(reason)
 }
 
-// Wait blocks until the controller's internals exit, and then returns the result of [Err].
+// Wait blocks until the controller's internals exit, and then returns the result of [Err]. It may be called at any
+// time, from any goroutine, including before anything was launched.
 func (c *Controller) Wait() error {
 	return c.impl.Wait()
 }
 
-// Err returns the first non-nil error recorded by the controller (including calls to [RequestStop]).
+// Called by the control loop at the end of startup (see [Controller.FinishStartup]). Any listeners that weren't
+// claimed via [WithListener] are closed, and if this process was started by [Controller.Upgrade], the old
+// process is told that we're ready.
+func (c *Controller) onStartupFinished() {
+	if err := closeUnclaimedListeners(); err != nil {
+		c.impl.Log.Warn("failed to close unclaimed listeners", "error", err)
+	}
+	if err := notifyUpgradeParent(); err != nil {
+		c.impl.Log.Warn("failed to notify the old process that the upgrade is ready", "error", err)
+	}
+}
+
+// Err returns the first non-nil error recorded by the controller (including calls to [RequestStop]). Errors from
+// [Optional] components are skipped, as they don't affect the outcome; they're only in [Controller.AllErrors].
 func (c *Controller) Err() error {
 	return c.impl.Err()
 }
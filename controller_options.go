@@ -2,16 +2,23 @@ package launch
 
 import (
 	"log/slog"
+	"os"
 	"time"
 
 	"github.com/spikesdivzero/launch-control/internal/controller"
+	"github.com/spikesdivzero/launch-control/internal/sdnotify"
 )
 
 type ControllerOption func(*controller.Controller)
 
 // Sets a logger for the controller to use.
 //
-// Primarily intended for debugging, and the records emitted are not guaranteed to be useful.
+// Records are emitted for each step of the lifecycle: launches, readiness attempts (at debug level), shutdown
+// stages, timeouts, abandoned components, and the final outcome. Records about a component carry a "component"
+// attribute, along with a "stage" attribute and a "duration" where relevant.
+//
+// The message text and attributes are meant for humans, and may change between releases. Use
+// [WithControllerObserver] for anything that needs to be stable.
 func WithControllerLogger(log *slog.Logger) ControllerOption {
 	if log == nil {
 		panic(optionNilArgError{"WithControllerLogger", "log"})
@@ -53,3 +60,151 @@ func WithControllerInternalAsyncGracePeriod(d time.Duration) ControllerOption {
 		c.AsyncGracePeriod = d
 	}
 }
+
+// Registers a function to be called with every lifecycle [Event] of the controller and its components.
+//
+// Events are delivered to each observer one at a time, in the order they happened. Each observer runs on its own
+// goroutine with its own queue, so a slow observer never blocks the controller, or any other observer. Events may
+// still be delivered after [Controller.Wait] returns.
+//
+// May be given more than once to register multiple observers.
+func WithControllerObserver(fn func(Event)) ControllerOption {
+	if fn == nil {
+		panic(optionNilArgError{"WithControllerObserver", "fn"})
+	}
+
+	return func(c *controller.Controller) {
+		addObserver(c, fn)
+	}
+}
+
+// Limits the total time the controller may take to shut down, e.g. to stay within Kubernetes'
+// terminationGracePeriodSeconds, after which the process is killed no matter what.
+//
+// As each component starts shutting down, its shutdown and completion timeouts are lowered to fit in whatever time
+// remains, less any time held back via [WithShutdownReserve] for the components that are stopped after it. A
+// component whose turn comes once there's no time left isn't shut down at all: it's reported as [ComponentAbandoned],
+// with an error recorded in [Controller.AllErrors].
+//
+// Both zero and negative durations mean there's no limit, which is the default.
+func WithControllerShutdownTimeout(d time.Duration) ControllerOption {
+	if d < 0 {
+		d = 0
+	}
+
+	return func(c *controller.Controller) {
+		c.ShutdownTimeout = d
+	}
+}
+
+// Has the controller listen for the given OS signals, typically [os.Interrupt] and [syscall.SIGTERM].
+//
+// The first signal received calls [Controller.RequestStop] with a [SignalError] naming the signal. If another
+// signal is received while stopping (see [WithEscalationSignals]), the shutdown is escalated: the stacks of all
+// goroutines are dumped to stderr, and the process exits immediately with [EscalationExitCode], skipping whatever
+// remains of the graceful shutdown.
+//
+// Signals are listened for from the moment the controller is created, until it's dead. The listener isn't a
+// component, so it doesn't take part in the shutdown order.
+func WithSignals(sigs ...os.Signal) ControllerOption {
+	if len(sigs) == 0 {
+		panic("WithSignals requires at least one signal")
+	}
+
+	return func(c *controller.Controller) {
+		c.Signals = sigs
+	}
+}
+
+// Sets which signals escalate the shutdown, once a signal from [WithSignals] has started it. By default, this is
+// the same set of signals given to [WithSignals], so that repeating a signal escalates.
+//
+// Signals given here that aren't also given to [WithSignals] escalate even if no stop signal was received first.
+// Has no effect without [WithSignals].
+func WithEscalationSignals(sigs ...os.Signal) ControllerOption {
+	if len(sigs) == 0 {
+		panic("WithEscalationSignals requires at least one signal")
+	}
+
+	return func(c *controller.Controller) {
+		c.EscalationSignals = sigs
+	}
+}
+
+// Has the controller call [Controller.Reload] whenever one of the given OS signals is received, typically
+// [syscall.SIGHUP]. A failed reload is logged.
+func WithReloadSignals(sigs ...os.Signal) ControllerOption {
+	if len(sigs) == 0 {
+		panic("WithReloadSignals requires at least one signal")
+	}
+
+	return func(c *controller.Controller) {
+		c.ReloadSignals = sigs
+	}
+}
+
+// Limits how long each of the components' reload functions (see [WithReload]) may take, whether the reload was
+// started by [Controller.Reload], or by one of the [WithReloadSignals]. A reload function that runs out of time
+// fails, the same as if it returned an error. The default is 30s.
+//
+// Both zero and negative durations mean there's no limit, other than the context given to [Controller.Reload].
+func WithReloadTimeout(d time.Duration) ControllerOption {
+	if d < 0 {
+		d = 0
+	}
+
+	return func(c *controller.Controller) {
+		c.ReloadTimeout = d
+	}
+}
+
+// Sets the command run by [Controller.Upgrade], e.g. to switch to a newly installed binary. By default, the current
+// executable is run again with the same arguments.
+func WithUpgradeCommand(path string, args ...string) ControllerOption {
+	if path == "" {
+		panic("WithUpgradeCommand: path must not be empty")
+	}
+
+	return func(c *controller.Controller) {
+		c.UpgradePath, c.UpgradeArgs = path, args
+	}
+}
+
+// Serves a control socket at the given path, so that operators on the same machine can check on and manage the
+// running application, e.g. via the launchctl command (see cmd/launchctl).
+//
+// Each connection carries a single [ControlRequest], answered with a [ControlResponse]. The supported commands are
+// "status", "stop" (which calls [Controller.RequestStop] with a [ControlStopError]), "reload" (which calls
+// [Controller.Reload]), and "dump-stacks" (which returns the stacks of all goroutines).
+//
+// The socket is created when the controller is, and is only accessible to the same user. A stale socket left behind
+// by an earlier process is replaced, but one that's still in use isn't, in which case the failure is logged and the
+// controller runs without it. The socket is removed once the controller is dead.
+func WithControlSocket(path string) ControllerOption {
+	if path == "" {
+		panic("WithControlSocket: path must not be empty")
+	}
+
+	return func(c *controller.Controller) {
+		c.ControlSocketPath = path
+	}
+}
+
+// Reports the controller's lifecycle to systemd, for use in `Type=notify` units.
+//
+// READY=1 is sent once startup has finished (see [Controller.FinishStartup]), and STOPPING=1 once the controller
+// starts shutting down, with STATUS= lines naming the component that's currently starting or stopping along the way.
+//
+// If the unit sets `WatchdogSec=`, WATCHDOG=1 is sent periodically for as long as the controller remains responsive,
+// so that systemd can restart the service if it's stalled. The pings are sent from their own goroutine, so a slow
+// reload or [Controller.StopComponent] doesn't hold them up. They stop once the controller starts shutting down,
+// leaving it to `TimeoutStopSec=` instead.
+//
+// Does nothing if $NOTIFY_SOCKET isn't set, such as when not running under systemd.
+func WithSystemdNotify() ControllerOption {
+	return func(c *controller.Controller) {
+		if n := sdnotify.FromEnv(); n != nil {
+			c.NotifySystemd(n, sdnotify.WatchdogInterval())
+		}
+	}
+}
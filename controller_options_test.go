@@ -1,11 +1,18 @@
 package launch
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
 	"github.com/spikesdivzero/launch-control/internal/controller"
 	"github.com/spikesdivzero/launch-control/internal/testutil"
 )
@@ -23,6 +30,23 @@ func TestWithControllerLogger(t *testing.T) {
 	})
 }
 
+func TestWithControllerShutdownTimeout(t *testing.T) {
+	tests := []struct {
+		argD, wantD time.Duration
+	}{
+		{-12, 0},
+		{0, 0},
+		{30 * time.Second, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.argD.String(), func(t *testing.T) {
+			c := controller.New(t.Context())
+			WithControllerShutdownTimeout(tt.argD)(c)
+			test.Eq(t, tt.wantD, c.ShutdownTimeout)
+		})
+	}
+}
+
 func TestWithControllerInternalAsyncGracePeriod(t *testing.T) {
 	t.Run("happy", func(t *testing.T) {
 		c := controller.New(t.Context())
@@ -46,3 +70,134 @@ func TestWithControllerInternalAsyncGracePeriod(t *testing.T) {
 		WithControllerInternalAsyncGracePeriod(time.Minute)
 	})
 }
+
+func TestWithControllerObserver(t *testing.T) {
+	c := controller.New(t.Context())
+
+	gotCh := make(chan Event, 4)
+	WithControllerObserver(func(ev Event) { gotCh <- ev })(c)
+
+	c.RequestStop(errors.New("bye"))
+	ev := <-gotCh
+	test.Eq(t, EventErrorRecorded, ev.Kind)
+	test.EqError(t, ev.Err, "bye")
+	test.Eq(t, 1, ev.Seq)
+
+	t.Run("panics on nil", func(t *testing.T) {
+		defer testutil.WantPanic(t, optionNilArgError{"WithControllerObserver", "fn"}.Error())
+		WithControllerObserver(nil)
+	})
+}
+
+func TestWithSignals(t *testing.T) {
+	c := controller.New(t.Context())
+	WithSignals(os.Interrupt, syscall.SIGTERM)(c)
+	test.Eq(t, []os.Signal{os.Interrupt, syscall.SIGTERM}, c.Signals)
+
+	t.Run("panics on none", func(t *testing.T) {
+		defer testutil.WantPanic(t, "WithSignals requires at least one signal")
+		WithSignals()
+	})
+}
+
+func TestWithEscalationSignals(t *testing.T) {
+	c := controller.New(t.Context())
+	WithEscalationSignals(syscall.SIGQUIT)(c)
+	test.Eq(t, []os.Signal{syscall.SIGQUIT}, c.EscalationSignals)
+
+	t.Run("panics on none", func(t *testing.T) {
+		defer testutil.WantPanic(t, "WithEscalationSignals requires at least one signal")
+		WithEscalationSignals()
+	})
+}
+
+func TestSignalError(t *testing.T) {
+	err := error(SignalError{syscall.SIGTERM})
+	test.EqError(t, err, "received signal: terminated")
+
+	var sigErr SignalError
+	test.True(t, errors.As(err, &sigErr))
+	test.Eq[os.Signal](t, syscall.SIGTERM, sigErr.Signal)
+}
+
+func TestWithReloadSignals(t *testing.T) {
+	c := controller.New(t.Context())
+	WithReloadSignals(syscall.SIGHUP)(c)
+	test.Eq(t, []os.Signal{syscall.SIGHUP}, c.ReloadSignals)
+
+	t.Run("panics on none", func(t *testing.T) {
+		defer testutil.WantPanic(t, "WithReloadSignals requires at least one signal")
+		WithReloadSignals()
+	})
+}
+
+func TestWithReloadTimeout(t *testing.T) {
+	test.Eq(t, 30*time.Second, controller.New(t.Context()).ReloadTimeout)
+
+	tests := []struct {
+		argD, wantD time.Duration
+	}{
+		{-12, 0},
+		{0, 0},
+		{time.Minute, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.argD.String(), func(t *testing.T) {
+			c := controller.New(t.Context())
+			WithReloadTimeout(tt.argD)(c)
+			test.Eq(t, tt.wantD, c.ReloadTimeout)
+		})
+	}
+}
+
+func TestWithUpgradeCommand(t *testing.T) {
+	c := controller.New(t.Context())
+	WithUpgradeCommand("/usr/local/bin/app", "-v")(c)
+	test.Eq(t, "/usr/local/bin/app", c.UpgradePath)
+	test.Eq(t, []string{"-v"}, c.UpgradeArgs)
+
+	t.Run("panics on empty path", func(t *testing.T) {
+		defer testutil.WantPanic(t, "WithUpgradeCommand: path must not be empty")
+		WithUpgradeCommand("")
+	})
+}
+
+func TestWithSystemdNotify(t *testing.T) {
+	t.Run("not under systemd", func(t *testing.T) {
+		t.Setenv("NOTIFY_SOCKET", "")
+		c := controller.New(t.Context())
+		WithSystemdNotify()(c) // nothing to check, other than it being harmless
+	})
+
+	t.Run("under systemd", func(t *testing.T) {
+		// Socket paths are limited to ~100 bytes, which t.TempDir can exceed.
+		dir, err := os.MkdirTemp("", "sdnotify")
+		must.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "notify.sock")
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+		must.NoError(t, err)
+		defer conn.Close()
+		t.Setenv("NOTIFY_SOCKET", path)
+
+		ctrl := NewController(t.Context(), WithSystemdNotify())
+		ctrl.Launch("test", WithStartStop(
+			func(context.Context) error { return nil },
+			func(context.Context) error { return nil }))
+		defer ctrl.Wait()
+		defer ctrl.RequestStop(nil)
+		must.NoError(t, ctrl.FinishStartup())
+
+		// The first datagram is the component starting, so look for the one after.
+		var got string
+		must.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		for range 2 {
+			buf := make([]byte, 256)
+			size, err := conn.Read(buf)
+			must.NoError(t, err)
+			got = string(buf[:size])
+		}
+		test.Eq(t, "READY=1\nSTATUS=Running", got)
+	})
+}
@@ -0,0 +1,94 @@
+package launch
+
+import (
+	"time"
+
+	"github.com/spikesdivzero/launch-control/internal/controller"
+)
+
+// An EventKind identifies what an [Event] describes, and so which of its fields are set.
+type EventKind int
+
+const (
+	// The controller moved between lifecycle states. Sets PrevState and State, e.g. "Alive" to "Dying".
+	EventControllerState = EventKind(controller.EventControllerState)
+
+	// A component was accepted by [Controller.Launch] or [Controller.LaunchGroup]. Sets Component.
+	EventLaunchRequested = EventKind(controller.EventLaunchRequested)
+
+	// A component's Start returned successfully. Sets Component.
+	EventComponentStarted = EventKind(controller.EventComponentStarted)
+
+	// A component passed its readiness check. Sets Component.
+	EventComponentReady = EventKind(controller.EventComponentReady)
+
+	// A component failed. Sets Component, Stage, and Err.
+	EventComponentFailed = EventKind(controller.EventComponentFailed)
+
+	// A single [WithCheckReady] call returned. Sets Component, Attempt (starting at 1), Ready, and Err.
+	EventCheckReadyAttempt = EventKind(controller.EventCheckReadyAttempt)
+
+	// A component's shutdown entered a new stage: "quiesce" (see [WithQuiesce]), "impl", "context", or "abandon".
+	// Sets Component and Stage.
+	EventShutdownStage = EventKind(controller.EventShutdownStage)
+
+	// An error was recorded, and will be included in [Controller.AllErrors]. Sets Err, along with Component and
+	// Stage if the error came from a component (as opposed to [Controller.RequestStop]).
+	EventErrorRecorded = EventKind(controller.EventErrorRecorded)
+
+	// Startup finished, i.e. every launched component became ready, and nothing else was launched for a short while.
+	// See [Status.StartupFinished].
+	EventStartupFinished = EventKind(controller.EventStartupFinished)
+)
+
+func (k EventKind) String() string {
+	return controller.EventKind(k).String()
+}
+
+// An Event describes a single moment in the lifecycle of the controller or one of its components, as delivered to
+// observers registered with [WithControllerObserver] or [Controller.AddObserver]. Which fields are set depends on the
+// Kind.
+type Event struct {
+	// Seq increases by one with each event, starting at 1.
+	Seq       uint64
+	Kind      EventKind
+	Time      time.Time
+	Component string
+
+	PrevState string
+	State     string
+
+	Attempt int
+	Ready   bool
+
+	Stage string
+	Err   error
+}
+
+func eventFromImpl(ev controller.Event) Event {
+	return Event{
+		Seq:       ev.Seq,
+		Kind:      EventKind(ev.Kind),
+		Time:      ev.Time,
+		Component: ev.Component,
+		PrevState: ev.PrevState,
+		State:     ev.State,
+		Attempt:   ev.Attempt,
+		Ready:     ev.Ready,
+		Stage:     ev.Stage,
+		Err:       ev.Err,
+	}
+}
+
+// AddObserver registers fn to be called with each [Event] from now on, in the same way as [WithControllerObserver].
+// Any events from before the call are missed, so prefer the option where the full history matters.
+func (c *Controller) AddObserver(fn func(Event)) {
+	if fn == nil {
+		panic(optionNilArgError{"AddObserver", "fn"})
+	}
+	addObserver(c.impl, fn)
+}
+
+func addObserver(c *controller.Controller, fn func(Event)) {
+	c.AddObserver(func(ev controller.Event) { fn(eventFromImpl(ev)) })
+}
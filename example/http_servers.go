@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,7 +15,8 @@ type httpServer struct {
 	addr string
 	mux  *http.ServeMux
 
-	srv *http.Server
+	srv      *http.Server
+	inFlight atomic.Int64
 }
 
 func newBaseHttpServer(log *slog.Logger, addr string) *httpServer {
@@ -28,8 +30,12 @@ func newBaseHttpServer(log *slog.Logger, addr string) *httpServer {
 
 func (h *httpServer) Run(ctx context.Context) error {
 	h.srv = &http.Server{
-		Addr:    h.addr,
-		Handler: h.mux,
+		Addr: h.addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.inFlight.Add(1)
+			defer h.inFlight.Add(-1)
+			h.mux.ServeHTTP(w, r)
+		}),
 	}
 
 	err := h.srv.ListenAndServe()
@@ -39,6 +45,11 @@ func (h *httpServer) Run(ctx context.Context) error {
 	return fmt.Errorf("http.Server.ListenAndServe returned %w", err)
 }
 
+// The number of requests currently being served.
+func (h *httpServer) InFlight() int {
+	return int(h.inFlight.Load())
+}
+
 func (h *httpServer) Shutdown(ctx context.Context) error {
 	err := h.srv.Shutdown(ctx)
 	if err != nil {
@@ -48,55 +59,31 @@ func (h *httpServer) Shutdown(ctx context.Context) error {
 	return nil
 }
 
-type httpMgmtServer struct {
-	httpServer
-
-	appReady bool
-}
-
-func NewHttpMgmtServer(log *slog.Logger, requestShutdown func()) *httpMgmtServer {
-	s := &httpMgmtServer{
-		httpServer: *newBaseHttpServer(log, ":8844"),
-	}
+func NewHttpMgmtServer(log *slog.Logger, health, admin http.Handler) *httpServer {
+	s := newBaseHttpServer(log, ":8844")
 
 	s.mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("example mgmt server"))
 	}))
 
-	// An alternate way to shutdown the service, for example via a k8s hook.
+	// The admin handler shows the state of each component (/_/admin/status) and any errors
+	// (/_/admin/errors), and provides an alternate way to shutdown the service, for example via
+	// a k8s hook (POST /_/admin/stop), or to stop a single component (POST /_/admin/stop/data).
 	//
-	// This probably should be limited to POST, but for demo purposes, all methods work.
-	s.mux.Handle("/_/shutdown", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestShutdown()
-		w.Write([]byte("shutdown requested"))
-	}))
-
-	s.mux.Handle("/_/health", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// As long as the app is alive, then it's healty. A real app may have different
-		// conditions.
-		w.Write([]byte("happy as a clam"))
-	}))
+	// It doesn't authenticate anything itself. A real app would wrap it in a middleware that
+	// does, or keep the mgmt server off of any public network.
+	s.mux.Handle("/_/admin/", http.StripPrefix("/_/admin", admin))
 
 	// Your application can be healthy without being ready/willing to accept new traffic.
 	//
 	// Let's assume that you're using some sort of layer 7 traffic management software that
-	// supports endpoint readiness probes (envoy, haproxy, whatever).
+	// supports endpoint readiness probes (envoy, haproxy, whatever), or k8s probes.
 	//
-	// In this case, you wouldn't want to advertise your service as ready to accept traffic
-	// until e.g. you've loaded any runtime/dynamic config, and successfully verified your
-	// connnection to your data stores.
-	//
-	// Additionally, when shutting down, you may want to mark the service as "not ready", and
-	// wait a bit for the router to detect the status. Only then might you want to proceed
-	// with a normal graceful shutdown, to minimize the chance of any undesirable errors.
-	s.mux.Handle("/_/ready", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if s.appReady {
-			w.Write([]byte("OK"))
-		} else {
-			w.WriteHeader(http.StatusServiceUnavailable)
-			w.Write([]byte("please stop sending traffic here"))
-		}
-	}))
+	// The health handler answers these from the controller's own state: /_/livez for as long as
+	// the app is running, /_/startupz once it's finished starting up, and /_/readyz only while
+	// every component is ready. As soon as a shutdown starts, /_/readyz fails, before any of
+	// the components are stopped. Try /_/readyz?verbose to see the details.
+	s.mux.Handle("/_/", http.StripPrefix("/_", health))
 
 	s.mux.Handle("/_/metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("your metrics output"))
@@ -105,13 +92,18 @@ func NewHttpMgmtServer(log *slog.Logger, requestShutdown func()) *httpMgmtServer
 	return s
 }
 
-func (s *httpMgmtServer) setReadyState(v bool) {
-	s.appReady = v
-}
-
-func NewHttpAppServer(log *slog.Logger) *httpServer {
+func NewHttpAppServer(log *slog.Logger, ready func() bool) *httpServer {
 	s := newBaseHttpServer(log, ":8845")
 
+	// For routers that probe the app's own port. It fails as soon as the readiness gate closes.
+	s.mux.HandleFunc("/ready", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !ready() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ready"))
+	}))
+
 	s.mux.HandleFunc("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("your application stuff would be here"))
 	}))
@@ -2,9 +2,9 @@ package main
 
 import (
 	"context"
-	"errors"
 	"log/slog"
 	"os"
+	"syscall"
 	"time"
 
 	"github.com/dpotapov/slogpfx"
@@ -38,19 +38,18 @@ func main() {
 	// Caveat: run styles and check-ready should not be here, as they can only be provided once per component.
 	defaultOpts := launch.WithBundledOptions()
 
-	ctrl := launch.NewController(ctx, launch.WithControllerLogger(log))
-
-	sigint := IntteruptListener{
-		Log: log.With("prefix", "IntteruptListener"),
-	}
-	ctrl.Launch("sigint",
-		defaultOpts,
-		launch.WithRun(sigint.Run, sigint.Shutdown),
+	// Even if you're using an HTTP-based hook to request a shutdown of your application, you may still want to
+	// handle signals, so that your devs can verify shutdown behaviors locally (without needing to run a separate
+	// curl request). Hitting ^C a second time skips the rest of the graceful shutdown.
+	ctrl := launch.NewController(ctx,
+		launch.WithControllerLogger(log),
+		launch.WithSignals(os.Interrupt, syscall.SIGTERM),
 	)
 
 	mgmt := NewHttpMgmtServer(
 		log.With("prefix", "http:mgmt"),
-		func() { ctrl.RequestStop(errors.New("stop requested via http mgmt")) },
+		launch.HealthHandler(ctrl),
+		launch.AdminHandler(ctrl),
 	)
 	ctrl.Launch("http-mgmt",
 		defaultOpts,
@@ -64,38 +63,36 @@ func main() {
 		launch.WithCheckReady(data.CheckReady),
 	)
 
-	app := NewHttpAppServer(log.With("prefix", "http:app"))
+	// The readiness probes start failing as soon as the shutdown begins, but it takes a moment for the
+	// router to notice. The gate is launched last, so it's the first to be stopped: it closes, and then
+	// holds off the rest of the shutdown for at least 5s, giving the router time to catch up. After that,
+	// it waits for the requests still in flight to finish, for up to another 5s.
+	//
+	// Without MinDrain, the drain would usually end straight away, as there's rarely anything in flight
+	// at the very moment the gate closes, while the router is still sending requests our way.
+	//
+	// How long exactly is a question I won't presume to answer for you.
+	var app *httpServer
+	gate := launch.ReadinessGate(launch.ReadinessGateOptions{
+		DrainPeriod: 10 * time.Second,
+		MinDrain:    5 * time.Second,
+		InFlight:    func() int { return app.InFlight() },
+	})
+
+	app = NewHttpAppServer(log.With("prefix", "http:app"), gate.IsOpen)
 	ctrl.Launch("http-app",
 		defaultOpts,
 		launch.WithRun(app.Run, app.Shutdown),
 	)
 
-	// This one's a bit of an odd one, but it exists to show how we
-	ctrl.Launch("ready-state",
-		defaultOpts,
-		launch.WithStartStop(
-			func(ctx context.Context) error {
-				// Once we start up, we're ready to accept traffic
-				mgmt.setReadyState(true)
-				return nil
-			},
-			func(ctx context.Context) error {
-				// And as we're shutting down, we're no longer willing to accept traffic.
-				mgmt.setReadyState(false)
-
-				// After marking the service as no longer ready to receive traffic, we should
-				// wait before proceeding to shut down the rest of the way.
-				//
-				// How long exactly is a question I won't presume to answer for you.
-				time.Sleep(5 * time.Second)
-
-				return nil
-			},
-		),
-	)
+	ctrl.Launch("readiness-gate", gate.Component())
+	ctrl.FinishStartup()
 
-	log.Info("Started up; you can cancel it via ^C or curl http://localhost:8844/_/shutdown")
+	log.Info("Started up; you can cancel it via ^C or curl -X POST http://localhost:8844/_/admin/stop")
 	if err := ctrl.Wait(); err != nil {
 		log.Error("Controller wait returned an error", "err", err)
 	}
+	if reason, ok := ctrl.ShutdownReason(); ok {
+		log.Info("Controller shut down", "trigger", reason.Trigger, "reason", reason.Err)
+	}
 }
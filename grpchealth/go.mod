@@ -0,0 +1,20 @@
+module github.com/spikesdivzero/launch-control/grpchealth
+
+go 1.25.0
+
+replace github.com/spikesdivzero/launch-control v0.0.0 => ..
+
+require (
+	github.com/shoenig/test v1.12.1
+	github.com/spikesdivzero/launch-control v0.0.0
+	google.golang.org/grpc v1.82.1
+)
+
+require (
+	github.com/google/go-cmp v0.7.0 // indirect
+	golang.org/x/net v0.53.0 // indirect
+	golang.org/x/sys v0.43.0 // indirect
+	golang.org/x/text v0.36.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260414002931-afd174a4e478 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+)
@@ -0,0 +1,165 @@
+// Package grpchealth implements the gRPC health checking protocol (grpc.health.v1) on top of a launch.Controller, so
+// that load balancers probing a gRPC service get the same answers as launch.HealthHandler's /readyz.
+//
+// It lives in its own module, so that applications that don't use gRPC don't pull in its dependencies.
+package grpchealth
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/spikesdivzero/launch-control"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// An Option configures a [Server].
+type Option func(*Server)
+
+// Adds a named service, which is serving while the controller is and the given components are ready. The
+// overall status, under the empty service name, always covers every component, as launch.Status.Ready does.
+func WithService(name string, components ...string) Option {
+	if name == "" {
+		panic("WithService: name must not be empty")
+	}
+	if len(components) == 0 {
+		panic("WithService requires at least one component")
+	}
+
+	return func(s *Server) {
+		s.services[name] = components
+	}
+}
+
+// A Server implements [healthpb.HealthServer], deriving each status from the controller's state:
+//
+//   - The overall status (the empty service name) is SERVING only while launch.Status.Ready holds, the same as
+//     launch.HealthHandler's /readyz. In particular, a failed Optional component doesn't make it NOT_SERVING.
+//   - A service added via [WithService] is SERVING while the controller is Alive, startup has finished, and each of
+//     its own components is ready. Listing an Optional component makes the service depend on it, so the service is
+//     NOT_SERVING if that component fails.
+//
+// Anything else is NOT_SERVING, including as soon as the controller starts shutting down. Watch streams are
+// updated as the controller's state changes, so they flip to NOT_SERVING at the start of the shutdown, before any
+// component is stopped.
+type Server struct {
+	healthpb.UnimplementedHealthServer
+
+	ctrl     launch.Controller
+	services map[string][]string
+
+	// Closed and replaced whenever the controller emits an event, to wake up Watch streams.
+	mu        sync.Mutex
+	changedCh chan struct{}
+}
+
+// Returns a new Server for the controller. Register it with [healthpb.RegisterHealthServer].
+func NewServer(ctrl launch.Controller, opts ...Option) *Server {
+	s := &Server{
+		ctrl:      ctrl,
+		services:  map[string][]string{},
+		changedCh: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	ctrl.AddObserver(func(launch.Event) { s.notifyChanged() })
+	return s
+}
+
+func (s *Server) notifyChanged() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	close(s.changedCh)
+	s.changedCh = make(chan struct{})
+}
+
+func (s *Server) changed() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.changedCh
+}
+
+// Returns the status of the named service, or false if it's unknown.
+func (s *Server) servingStatus(service string) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	components, ok := s.services[service]
+	if service != "" && !ok {
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, false
+	}
+
+	st := s.ctrl.Status()
+	if service == "" {
+		if !st.Ready() {
+			return healthpb.HealthCheckResponse_NOT_SERVING, true
+		}
+		return healthpb.HealthCheckResponse_SERVING, true
+	}
+	if st.State != "Alive" || !st.StartupFinished {
+		return healthpb.HealthCheckResponse_NOT_SERVING, true
+	}
+
+	ready := map[string]bool{}
+	for _, cs := range st.Components {
+		ready[cs.Name] = cs.State == launch.ComponentReady
+	}
+	for _, name := range components {
+		if !ready[name] { // includes components that were never launched, or have since been stopped
+			return healthpb.HealthCheckResponse_NOT_SERVING, true
+		}
+	}
+	return healthpb.HealthCheckResponse_SERVING, true
+}
+
+// Check implements [healthpb.HealthServer]. Unknown services are reported with codes.NotFound, as the protocol
+// requires.
+func (s *Server) Check(
+	ctx context.Context,
+	req *healthpb.HealthCheckRequest,
+) (*healthpb.HealthCheckResponse, error) {
+	st, ok := s.servingStatus(req.GetService())
+	if !ok {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("unknown service %q", req.GetService()))
+	}
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+// Watch implements [healthpb.HealthServer]. The current status is sent straight away, followed by each change.
+// Unknown services are reported as SERVICE_UNKNOWN, as the protocol requires.
+func (s *Server) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+	for {
+		// Grab the channel before reading the status, so that no change can slip through in between.
+		changedCh := s.changed()
+
+		st, _ := s.servingStatus(req.GetService())
+		if st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return status.Error(codes.Canceled, "stream has ended")
+			}
+			last = st
+		}
+
+		select {
+		case <-changedCh:
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		}
+	}
+}
+
+// List implements [healthpb.HealthServer], returning the overall status along with every service added via
+// [WithService].
+func (s *Server) List(ctx context.Context, req *healthpb.HealthListRequest) (*healthpb.HealthListResponse, error) {
+	resp := &healthpb.HealthListResponse{Statuses: map[string]*healthpb.HealthCheckResponse{}}
+	for _, name := range append([]string{""}, slices.Collect(maps.Keys(s.services))...) {
+		st, _ := s.servingStatus(name)
+		resp.Statuses[name] = &healthpb.HealthCheckResponse{Status: st}
+	}
+	return resp, nil
+}
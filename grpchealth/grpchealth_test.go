@@ -0,0 +1,170 @@
+package grpchealth
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Serves the health server over an in-process connection, returning a client for it.
+func newTestingClient(t *testing.T, hs *Server) healthpb.HealthClient {
+	ln := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	must.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func nopStartStop() launch.ComponentOption {
+	return launch.WithStartStop(
+		func(context.Context) error { return nil },
+		func(context.Context) error { return nil })
+}
+
+func TestServer(t *testing.T) {
+	const (
+		serving    = healthpb.HealthCheckResponse_SERVING
+		notServing = healthpb.HealthCheckResponse_NOT_SERVING
+		unknown    = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+	)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+
+	ctrl := launch.NewController(ctx)
+	client := newTestingClient(t, NewServer(ctrl,
+		WithService("greeter", "api"),
+		WithService("missing", "nope")))
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		must.NoError(t, err)
+		return resp.GetStatus()
+	}
+	recv := func(stream healthpb.Health_WatchClient) healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := stream.Recv()
+		must.NoError(t, err)
+		return resp.GetStatus()
+	}
+
+	overall, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	must.NoError(t, err)
+	test.Eq(t, notServing, recv(overall))
+
+	stopCh := make(chan struct{})
+	ctrl.Launch("db", nopStartStop())
+	ctrl.Launch("api", launch.WithStartStop(
+		func(context.Context) error { return nil },
+		func(context.Context) error { <-stopCh; return nil }))
+
+	// Not serving until startup has finished.
+	test.Eq(t, notServing, check(""))
+	test.Eq(t, notServing, check("greeter"))
+
+	must.NoError(t, ctrl.FinishStartup())
+	waitCh := make(chan error)
+	go func() { waitCh <- ctrl.Wait() }()
+	test.Eq(t, serving, recv(overall))
+	test.Eq(t, serving, check(""))
+	test.Eq(t, serving, check("greeter"))
+	test.Eq(t, notServing, check("missing")) // the component doesn't exist
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "other"})
+	test.Eq(t, codes.NotFound, status.Code(err))
+
+	other, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "other"})
+	must.NoError(t, err)
+	test.Eq(t, unknown, recv(other))
+
+	list, err := client.List(ctx, &healthpb.HealthListRequest{})
+	must.NoError(t, err)
+	test.MapLen(t, 3, list.GetStatuses())
+	test.Eq(t, serving, list.GetStatuses()["greeter"].GetStatus())
+
+	// The shutdown flips the status straight away, while "api" is still being stopped.
+	ctrl.RequestStop(nil)
+	test.Eq(t, notServing, recv(overall))
+	test.Eq(t, notServing, check("greeter"))
+	test.Eq(t, launch.ComponentStopping, ctrl.Status().Components[1].State)
+
+	close(stopCh)
+	must.NoError(t, <-waitCh)
+	test.Eq(t, notServing, check(""))
+}
+
+func TestServer_failedOptional(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+
+	ctrl := launch.NewController(ctx)
+	client := newTestingClient(t, NewServer(ctrl, WithService("cached", "cache")))
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		must.NoError(t, err)
+		return resp.GetStatus()
+	}
+
+	overall, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	must.NoError(t, err)
+
+	ctrl.Launch("api", nopStartStop())
+	h := ctrl.Launch("cache",
+		launch.WithCriticality(launch.Optional),
+		nopStartStop(),
+		launch.WithCheckReadyMaxAttempts(1),
+		launch.WithCheckReady(func(context.Context) (bool, error) { return false, nil }))
+	must.NoError(t, ctrl.FinishStartup())
+
+	waitCh := make(chan error)
+	go func() { waitCh <- ctrl.Wait() }()
+	for {
+		resp, err := overall.Recv()
+		must.NoError(t, err)
+		if resp.GetStatus() == healthpb.HealthCheckResponse_SERVING {
+			break
+		}
+	}
+	must.Eq(t, launch.ComponentFailed, h.State())
+
+	// The controller keeps running without the cache, and so does the overall status. Only the service that
+	// depends on it stops serving.
+	test.Eq(t, healthpb.HealthCheckResponse_SERVING, check(""))
+	test.Eq(t, healthpb.HealthCheckResponse_NOT_SERVING, check("cached"))
+
+	ctrl.RequestStop(nil)
+	<-waitCh
+}
+
+func TestWithService(t *testing.T) {
+	t.Run("panics on empty name", func(t *testing.T) {
+		defer func() { test.Eq(t, "WithService: name must not be empty", recover()) }()
+		WithService("", "api")
+	})
+
+	t.Run("panics without components", func(t *testing.T) {
+		defer func() { test.Eq(t, "WithService requires at least one component", recover()) }()
+		WithService("greeter")
+	})
+}
@@ -0,0 +1,108 @@
+package launch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// The longest we'll wait on an HAProxy agent-check connection, so that a stuck peer can't hold up the shutdown.
+const haproxyAgentWriteTimeout = 5 * time.Second
+
+// HAProxyAgentCheck returns the options for a built-in component that answers HAProxy agent checks (see
+// `agent-check` in the HAProxy docs) on the given TCP address, e.g. ":8846". Launch it as a component of its own:
+//
+//	ctrl.Launch("haproxy-agent", launch.HAProxyAgentCheck(ctrl, ":8846"))
+//
+// Each connection is answered with a single line derived from the controller's state, and then closed:
+//
+//   - "drain" as soon as the controller starts shutting down, so HAProxy stops sending new traffic before the
+//     servers behind it are stopped.
+//   - "ready" while the controller is ready, as reported by [Status.Ready]. A failed [Optional] component doesn't
+//     stop the agent from answering "ready".
+//   - "drain" if a component stops being ready after startup has finished, e.g. while it's being restarted. This
+//     is usually transient, so existing connections are left alone, and "ready" is sent again once it recovers.
+//   - "maint" otherwise, e.g. while starting up.
+//
+// As components are stopped in reverse launch order, launch the agent before the servers it reports on, so that it
+// keeps answering "drain" while they shut down.
+func HAProxyAgentCheck(ctrl Controller, addr string) ComponentOption {
+	a := &haproxyAgent{ctrl: ctrl, addr: addr}
+	return WithRun(a.run, a.shutdown)
+}
+
+type haproxyAgent struct {
+	ctrl Controller
+	addr string
+
+	mu       sync.Mutex
+	ln       net.Listener
+	stopping bool
+}
+
+func (a *haproxyAgent) run(ctx context.Context) error {
+	var lc net.ListenConfig
+	ln, err := lc.Listen(ctx, "tcp", a.addr)
+	if err != nil {
+		return fmt.Errorf("haproxy agent: %w", err)
+	}
+
+	a.mu.Lock()
+	if a.stopping {
+		a.mu.Unlock()
+		return ln.Close()
+	}
+	a.ln = ln
+	a.mu.Unlock()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		conn, err := ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("haproxy agent: %w", err)
+		}
+
+		wg.Go(func() {
+			defer conn.Close()
+			_ = conn.SetWriteDeadline(time.Now().Add(haproxyAgentWriteTimeout))
+			_, _ = fmt.Fprintf(conn, "%s\n", haproxyAgentState(a.ctrl.Status()))
+		})
+	}
+}
+
+func (a *haproxyAgent) shutdown(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stopping = true
+	if a.ln != nil {
+		return a.ln.Close()
+	}
+	return nil
+}
+
+func haproxyAgentState(s Status) string {
+	switch s.State {
+	case "Dying", "Dead":
+		return "drain"
+	case "Alive":
+		switch {
+		case s.Ready():
+			return "ready"
+		case s.StartupFinished:
+			// Most likely a component that's being restarted. Keep the existing connections going until it's back.
+			return "drain"
+		default:
+			return "maint"
+		}
+	default:
+		return "maint"
+	}
+}
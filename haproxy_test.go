@@ -0,0 +1,99 @@
+package launch
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func Test_haproxyAgentState(t *testing.T) {
+	ready := ComponentStatus{Name: "a", State: ComponentReady}
+	starting := ComponentStatus{Name: "b", State: ComponentStarting}
+	failedOptional := ComponentStatus{Name: "c", State: ComponentFailed, Criticality: Optional}
+
+	tests := []struct {
+		name   string
+		status Status
+		want   string
+	}{
+		{"new", Status{State: "New"}, "maint"},
+		{"starting up", Status{State: "Alive", Components: []ComponentStatus{ready}}, "maint"},
+		{"ready", Status{State: "Alive", StartupFinished: true, Components: []ComponentStatus{ready}}, "ready"},
+		{"restarting", Status{State: "Alive", StartupFinished: true, Components: []ComponentStatus{ready, starting}},
+			"drain"},
+		{"failed optional",
+			Status{State: "Alive", StartupFinished: true, Components: []ComponentStatus{ready, failedOptional}},
+			"ready"},
+		{"dying", Status{State: "Dying", StartupFinished: true, Components: []ComponentStatus{ready}}, "drain"},
+		{"dead", Status{State: "Dead"}, "drain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.Eq(t, tt.want, haproxyAgentState(tt.status))
+		})
+	}
+}
+
+func TestHAProxyAgentCheck(t *testing.T) {
+	// Find a free port for the agent.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	must.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	agentCheck := func() string {
+		t.Helper()
+		conn, err := net.Dial("tcp", addr)
+		must.NoError(t, err)
+		defer conn.Close()
+		b, err := io.ReadAll(conn)
+		must.NoError(t, err)
+		return string(b)
+	}
+	eventually := func(cond func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(time.Millisecond) {
+			must.True(t, time.Now().Before(deadline))
+		}
+	}
+
+	ctrl := NewController(t.Context())
+	ctrl.Launch("haproxy-agent", HAProxyAgentCheck(ctrl, addr))
+	eventually(func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	})
+	test.Eq(t, "maint\n", agentCheck())
+
+	// Every component being ready isn't enough, as the application may still have more to launch. Only
+	// FinishStartup ends the "maint" state, so this doesn't depend on how quickly the checks come in.
+	stopCh := make(chan struct{})
+	ctrl.Launch("http", WithStartStop(
+		func(context.Context) error { return nil },
+		func(context.Context) error { <-stopCh; return nil }))
+	test.Eq(t, "maint\n", agentCheck())
+
+	must.NoError(t, ctrl.FinishStartup())
+	test.Eq(t, "ready\n", agentCheck())
+
+	waitCh := make(chan error)
+	go func() { waitCh <- ctrl.Wait() }()
+
+	// The agent is still answering while "http" is being stopped.
+	ctrl.RequestStop(nil)
+	eventually(func() bool { return ctrl.Status().State == "Dying" })
+	test.Eq(t, "drain\n", agentCheck())
+
+	close(stopCh)
+	must.NoError(t, <-waitCh)
+	_, err = net.Dial("tcp", addr)
+	test.Error(t, err)
+}
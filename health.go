@@ -0,0 +1,101 @@
+package launch
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HealthHandler returns an [http.Handler] that serves Kubernetes-style health probes derived from the controller's
+// state:
+//
+//   - `/livez` succeeds until the controller is Dead. A controller that's shutting down is still alive, so that
+//     it isn't killed partway through draining.
+//   - `/readyz` succeeds only while the controller is Alive, startup has finished, and every component is
+//     [ComponentReady], apart from [Optional] components that have failed (see [Status.Ready]). It fails as soon as
+//     the controller starts shutting down, before any component is stopped.
+//   - `/startupz` succeeds once startup has finished (see [Status.StartupFinished]), and keeps succeeding after that.
+//
+// Failing probes respond with 503 Service Unavailable. Adding `?verbose` to any of them lists the controller's
+// state and that of every component, one per line, with `[+]` or `[-]` marking whether each passed.
+//
+// The handler only serves those exact paths; use [http.StripPrefix] to mount it elsewhere.
+func HealthHandler(ctrl Controller) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /livez", healthProbe{"livez", ctrl, livezCheck})
+	mux.Handle("GET /readyz", healthProbe{"readyz", ctrl, readyzCheck})
+	mux.Handle("GET /startupz", healthProbe{"startupz", ctrl, startupzCheck})
+	return mux
+}
+
+// A healthCheck reports whether the controller as a whole passes, and whether each component does. If
+// componentOK is nil, components don't factor into the check.
+type healthCheck struct {
+	controllerOK func(Status) bool
+	componentOK  func(ComponentStatus) bool
+}
+
+var (
+	livezCheck = healthCheck{
+		controllerOK: func(s Status) bool { return s.State != "Dead" },
+	}
+	readyzCheck = healthCheck{
+		controllerOK: func(s Status) bool { return s.State == "Alive" && s.StartupFinished },
+		componentOK:  ComponentStatus.CountsAsReady,
+	}
+	startupzCheck = healthCheck{
+		controllerOK: func(s Status) bool { return s.StartupFinished },
+	}
+)
+
+type healthProbe struct {
+	name  string
+	ctrl  Controller
+	check healthCheck
+}
+
+func (p healthProbe) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s := p.ctrl.Status()
+
+	var lines []string
+	mark := func(ok bool) string {
+		if ok {
+			return "[+]"
+		}
+		return "[-]"
+	}
+
+	ok := p.check.controllerOK(s)
+	lines = append(lines, fmt.Sprintf("%scontroller %s", mark(ok), s.State))
+	if p.check.componentOK != nil {
+		for _, cs := range s.Components {
+			compOK := p.check.componentOK(cs)
+			ok = ok && compOK
+
+			line := fmt.Sprintf("%s%s %s", mark(compOK), cs.Name, cs.State)
+			if (!compOK || cs.State == ComponentFailed) && cs.LastError != "" {
+				line += ": " + cs.LastError
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-store")
+
+	summary := p.name + " check passed"
+	if !ok {
+		summary = p.name + " check failed"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	switch {
+	case r.URL.Query().Has("verbose"):
+		fmt.Fprintln(w, strings.Join(append(lines, summary), "\n"))
+	case ok:
+		fmt.Fprintln(w, "ok")
+	default:
+		fmt.Fprintln(w, summary)
+	}
+}
@@ -0,0 +1,119 @@
+package launch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/synctest"
+
+	"github.com/shoenig/test"
+)
+
+func TestHealthHandler(t *testing.T) {
+	probe := func(h http.Handler, target string) (int, string) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w.Code, w.Body.String()
+	}
+
+	type want struct {
+		livez, readyz, startupz int
+	}
+	check := func(t *testing.T, h http.Handler, want want) {
+		t.Helper()
+		code, _ := probe(h, "/livez")
+		test.Eq(t, want.livez, code, test.Sprint("livez"))
+		code, _ = probe(h, "/readyz")
+		test.Eq(t, want.readyz, code, test.Sprint("readyz"))
+		code, _ = probe(h, "/startupz")
+		test.Eq(t, want.startupz, code, test.Sprint("startupz"))
+	}
+
+	const ok, unavailable = http.StatusOK, http.StatusServiceUnavailable
+
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := NewController(t.Context())
+		h := HealthHandler(ctrl)
+		check(t, h, want{ok, unavailable, unavailable})
+
+		stopCh := make(chan struct{})
+		ctrl.Launch("db", WithStartStop(
+			func(ctx context.Context) error { return nil },
+			func(ctx context.Context) error { <-stopCh; return nil }))
+		ctrl.Launch("http", WithStartStop(
+			func(ctx context.Context) error { return nil },
+			func(ctx context.Context) error { return nil }))
+
+		// Everything's ready, but startup isn't over until the application says so.
+		check(t, h, want{ok, unavailable, unavailable})
+
+		test.NoError(t, ctrl.FinishStartup())
+		check(t, h, want{ok, ok, ok})
+
+		waitCh := make(chan error)
+		go func() { waitCh <- ctrl.Wait() }()
+
+		code, body := probe(h, "/readyz")
+		test.Eq(t, ok, code)
+		test.Eq(t, "ok\n", body)
+
+		code, body = probe(h, "/readyz?verbose")
+		test.Eq(t, ok, code)
+		test.Eq(t, "[+]controller Alive\n[+]db Ready\n[+]http Ready\nreadyz check passed\n", body)
+
+		// Readiness fails as soon as shutdown starts, while the components are still being stopped.
+		ctrl.RequestStop(nil)
+		synctest.Wait()
+		check(t, h, want{ok, unavailable, ok})
+
+		code, body = probe(h, "/readyz")
+		test.Eq(t, unavailable, code)
+		test.Eq(t, "readyz check failed\n", body)
+
+		code, body = probe(h, "/readyz?verbose")
+		test.Eq(t, unavailable, code)
+		test.Eq(t, "[-]controller Dying\n[-]db Stopping\n[-]http Stopped\nreadyz check failed\n", body)
+
+		close(stopCh)
+		test.NoError(t, <-waitCh)
+		check(t, h, want{unavailable, unavailable, ok})
+
+		code, body = probe(h, "/livez?verbose")
+		test.Eq(t, unavailable, code)
+		test.Eq(t, "[-]controller Dead\nlivez check failed\n", body)
+	})
+
+	t.Run("failed component", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			ctrl := NewController(t.Context())
+			h := HealthHandler(ctrl)
+
+			ctrl.Launch("cache",
+				WithCriticality(Optional),
+				WithStartStop(
+					func(ctx context.Context) error { return nil },
+					func(ctx context.Context) error { return nil }),
+				WithCheckReadyMaxAttempts(1),
+				WithCheckReady(func(ctx context.Context) (bool, error) { return false, nil }))
+
+			test.NoError(t, ctrl.FinishStartup())
+
+			// The controller keeps running without an Optional component, so its failure doesn't fail readiness.
+			check(t, h, want{ok, ok, ok})
+
+			_, body := probe(h, "/readyz?verbose")
+			test.StrContains(t, body, "[+]cache Failed: ")
+
+			ctrl.RequestStop(nil)
+			ctrl.Wait()
+		})
+	})
+
+	t.Run("other methods", func(t *testing.T) {
+		h := HealthHandler(NewController(t.Context()))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/livez", nil))
+		test.Eq(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
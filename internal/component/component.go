@@ -2,6 +2,7 @@ package component
 
 import (
 	"context"
+	"log/slog"
 	"math"
 	"time"
 )
@@ -11,9 +12,20 @@ const NoTimeout time.Duration = 50 * (time.Hour * 24 * 365)
 
 const defaultAsyncGracePeriod = 100 * time.Millisecond
 
+// Restarting in a tight loop doesn't do anyone any favors, so unlike CheckReady, the default backoff isn't zero.
+const defaultRestartBackoff = time.Second
+
+// Quiesce runs before anything is shut down, so a hung one would hold up the whole shutdown. Unlike the other
+// timeouts, it's bounded by default.
+const defaultQuiesceTimeout = 30 * time.Second
+
 type ShutdownOptions struct {
 	CallTimeout       time.Duration
 	CompletionTimeout time.Duration
+
+	// Used by the controller, rather than the component itself.
+	Reserve        time.Duration
+	QuiesceTimeout time.Duration
 }
 
 type CheckReadyOptions struct {
@@ -22,6 +34,37 @@ type CheckReadyOptions struct {
 	MaxAttempts int
 }
 
+type RestartPolicy int
+
+const (
+	RestartNever RestartPolicy = iota
+	RestartOnFailure
+	RestartAlways
+)
+
+type Criticality int
+
+const (
+	Critical Criticality = iota
+	Optional
+)
+
+// Used by the controller, rather than the component itself.
+type RestartOptions struct {
+	Policy      RestartPolicy
+	MaxRestarts int
+	Window      time.Duration
+	Backoff     func() time.Duration
+}
+
+// Used by the controller, rather than the component itself. A nil Prepare means the component doesn't take part
+// in reloads.
+type ReloadOptions struct {
+	Prepare func(context.Context) error
+	Commit  func(context.Context) error
+	Abort   func(context.Context) error
+}
+
 type Component struct {
 	Name string
 
@@ -30,16 +73,30 @@ type Component struct {
 	ImplShutdown    func(context.Context) error
 	ShutdownOptions ShutdownOptions
 
+	// Optional. Used by the controller, rather than the component itself.
+	ImplQuiesce func(context.Context) error
+
 	ImplCheckReady    func(context.Context) (bool, error)
 	CheckReadyOptions CheckReadyOptions
 
+	// Names of the components this one depends on, for use by the controller. Nil means "everything launched
+	// before this component".
+	DependsOn []string
+
+	RestartOptions RestartOptions
+	Criticality    Criticality
+	ReloadOptions  ReloadOptions
+
 	// Values provided by by [ConnectController]
+	log              *slog.Logger
 	logError         func(stage string, err error)
 	notifyOnExited   func(error)
+	notifyCheckReady func(attempt int, ready bool, err error)
+	notifyShutdown   func(stage string)
 	asyncGracePeriod time.Duration
 
 	// Lifecycle-related state, created in [Start]
-	runCtxCancel context.CancelFunc
+	runCtxCancel context.CancelCauseFunc
 	doneCh       <-chan struct{}
 }
 
@@ -50,23 +107,38 @@ func New(name string) *Component {
 		ShutdownOptions: ShutdownOptions{
 			CallTimeout:       NoTimeout,
 			CompletionTimeout: NoTimeout,
+			QuiesceTimeout:    defaultQuiesceTimeout,
 		},
 		CheckReadyOptions: CheckReadyOptions{
 			CallTimeout: NoTimeout,
 			Backoff:     func() time.Duration { return 0 },
 			MaxAttempts: math.MaxInt,
 		},
+		RestartOptions: RestartOptions{
+			Policy:      RestartNever,
+			MaxRestarts: math.MaxInt,
+			Window:      NoTimeout,
+			Backoff:     func() time.Duration { return defaultRestartBackoff },
+		},
 
+		log:              slog.New(slog.DiscardHandler),
 		asyncGracePeriod: defaultAsyncGracePeriod,
 	}
 }
 
+// The log is used for the component's own lifecycle records, and is expected to already carry the component's name.
 func (c *Component) ConnectController(
+	log *slog.Logger,
 	logError func(stage string, err error),
 	notifyOnExited func(error),
+	notifyCheckReady func(attempt int, ready bool, err error),
+	notifyShutdown func(stage string),
 	asyncGracePeriod time.Duration,
 ) {
+	c.log = log
 	c.logError = logError
 	c.notifyOnExited = notifyOnExited
+	c.notifyCheckReady = notifyCheckReady
+	c.notifyShutdown = notifyShutdown
 	c.asyncGracePeriod = asyncGracePeriod
 }
@@ -7,6 +7,20 @@ import (
 	"github.com/spikesdivzero/launch-control/internal/lcerrors"
 )
 
+type shutdownCauseKey struct{}
+
+// Attaches the reason for a shutdown to ctx. When passed to [Component.Shutdown], the cause is visible to ImplShutdown
+// via [ShutdownCause], and is used as the cause when the run context is cancelled.
+func WithShutdownCause(ctx context.Context, cause error) context.Context {
+	return context.WithValue(ctx, shutdownCauseKey{}, cause)
+}
+
+// Returns the cause attached by [WithShutdownCause], if any.
+func ShutdownCause(ctx context.Context) error {
+	cause, _ := ctx.Value(shutdownCauseKey{}).(error)
+	return cause
+}
+
 func (c *Component) Shutdown(ctx context.Context) error {
 	// Stage 1: Prefer a normal shutdown via user-provided ImplShutdown
 	// Stage 2: If that fails, attempt a shutdown via context cancellation.
@@ -14,11 +28,18 @@ func (c *Component) Shutdown(ctx context.Context) error {
 	//
 	// Each Via function is responsible for checking isDead at the start, in order to keep
 	// testing the main Shutdown() function as simple as possible.
+	//
+	// The controller is notified as each stage is entered.
+	start := time.Now()
 	c.shutdownViaImpl(ctx)
 	c.shutdownViaContext(ctx)
 	if c.isDead() {
+		c.log.Debug("component shut down", "duration", time.Since(start))
 		return nil
 	} else {
+		c.notifyShutdown("abandon")
+		c.log.Error("component did not respond to shutdown, abandoning it", "stage", "abandon",
+			"duration", time.Since(start))
 		return lcerrors.ErrShutdownAbandonedNonResponsive
 	}
 }
@@ -37,6 +58,10 @@ func (c *Component) shutdownViaImpl(ctx context.Context) {
 	if c.isDead() {
 		return
 	}
+	c.notifyShutdown("impl")
+	c.log.Info("shutdown stage entered", "stage", "impl")
+	start := time.Now()
+	defer func() { c.log.Debug("shutdown stage finished", "stage", "impl", "duration", time.Since(start)) }()
 
 	ctx, ctxCancel := context.WithTimeoutCause(ctx, c.ShutdownOptions.CompletionTimeout,
 		lcerrors.ContextTimeoutError{Source: "Shutdown.CompletionTimeout"})
@@ -61,6 +86,8 @@ func (c *Component) shutdownViaImpl(ctx context.Context) {
 	select {
 	case <-ctx.Done():
 		// CompletionTimeout expired.
+		c.log.Warn("timed out waiting for run to exit", "stage", "impl", "duration", time.Since(start),
+			"error", context.Cause(ctx))
 		c.logError("shutdown (impl)", context.Cause(ctx))
 	case <-c.doneCh:
 		// ImplRun finished.
@@ -68,16 +95,20 @@ func (c *Component) shutdownViaImpl(ctx context.Context) {
 }
 
 // Returns nil on success. Error is just for internal test validations.
-func (c *Component) shutdownViaContext(context.Context) {
+func (c *Component) shutdownViaContext(ctx context.Context) {
 	if c.isDead() {
 		return
 	}
+	c.notifyShutdown("context")
+	c.log.Warn("shutdown stage entered", "stage", "context")
+	start := time.Now()
 
-	c.runCtxCancel()
+	c.runCtxCancel(ShutdownCause(ctx)) // a nil cause is reported as context.Canceled
 
 	select {
 	case <-c.doneCh:
 		// Responded successfully, and is now exited
+		c.log.Debug("shutdown stage finished", "stage", "context", "duration", time.Since(start))
 	case <-time.After(c.asyncGracePeriod):
 		// Did not respond, and is still alive
 	}
@@ -34,20 +34,27 @@ func TestComponent_Shutdown(t *testing.T) {
 					calls = append(calls, "ImplShutdown")
 					return nil
 				}
-				c.runCtxCancel = func() {
+				c.runCtxCancel = func(error) {
 					calls = append(calls, "runCtxCancel")
 					if !wantErr {
 						closeDone()
 					}
 				}
 
+				c.notifyShutdown = func(stage string) {
+					calls = append(calls, "stage "+stage)
+				}
+
 				c.logError = func(string, error) {} // We validate our calls to this elsewhere
 
 				err := c.Shutdown(ctx)
-				test.Eq(t, []string{"ImplShutdown", "runCtxCancel"}, calls)
 				if wantErr {
+					test.Eq(t, []string{
+						"stage impl", "ImplShutdown", "stage context", "runCtxCancel", "stage abandon",
+					}, calls)
 					test.Error(t, err)
 				} else {
+					test.Eq(t, []string{"stage impl", "ImplShutdown", "stage context", "runCtxCancel"}, calls)
 					test.NoError(t, err)
 				}
 			})
@@ -160,6 +167,9 @@ func TestComponent_shutdownViaImpl(t *testing.T) {
 					return tt.shutdown.err
 				}
 
+				var stages []string
+				c.notifyShutdown = func(stage string) { stages = append(stages, stage) }
+
 				// TODO: should we check this?
 				logErrorCalled := false
 				c.logError = func(stage string, err error) {
@@ -180,6 +190,11 @@ func TestComponent_shutdownViaImpl(t *testing.T) {
 
 				wantShutdownCalled := tt.name != "already dead" // So sue me...
 				test.Eq(t, wantShutdownCalled, shutdownCalled)
+				if wantShutdownCalled {
+					test.Eq(t, []string{"impl"}, stages)
+				} else {
+					test.SliceEmpty(t, stages)
+				}
 
 				wantLogErrorCalled := tt.wantLog != nil
 				test.Eq(t, wantLogErrorCalled, logErrorCalled)
@@ -230,10 +245,13 @@ func TestComponent_shutdownViaContext(t *testing.T) {
 				c.doneCh, ctrl.closeDone = testutil.ChanWithCloser[struct{}](0)
 
 				calledRunCtxCancel := false
-				c.runCtxCancel = func() {
+				c.runCtxCancel = func(error) {
 					calledRunCtxCancel = true
 				}
 
+				var stages []string
+				c.notifyShutdown = func(stage string) { stages = append(stages, stage) }
+
 				ctx, cancel := context.WithCancelCause(t.Context())
 				defer cancel(errors.New("test done"))
 
@@ -248,7 +266,46 @@ func TestComponent_shutdownViaContext(t *testing.T) {
 
 				wantCalled := tt.name != "already dead" // So sue me...
 				test.Eq(t, wantCalled, calledRunCtxCancel)
+				if wantCalled {
+					test.Eq(t, []string{"context"}, stages)
+				} else {
+					test.SliceEmpty(t, stages)
+				}
 			})
 		})
 	}
 }
+
+func TestComponent_ShutdownCause(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		cause := errors.New("stop requested")
+
+		var runCause, implCause error
+		c := newTestingComponent(t)
+		c.ImplRun = func(ctx context.Context) error {
+			<-ctx.Done()
+			runCause = context.Cause(ctx)
+			return nil
+		}
+		c.ImplShutdown = func(ctx context.Context) error {
+			implCause = ShutdownCause(ctx)
+			return nil // but Run keeps going, so the context stage is needed
+		}
+		c.ShutdownOptions.CompletionTimeout = time.Second
+		c.logError = func(string, error) {}
+		c.notifyOnExited = func(error) {}
+		c.notifyShutdown = func(string) {}
+
+		test.NoError(t, c.Start(t.Context()))
+		test.NoError(t, c.Shutdown(WithShutdownCause(t.Context(), cause)))
+		test.Eq(t, cause, implCause)
+		test.Eq(t, cause, runCause)
+	})
+}
+
+func TestShutdownCause(t *testing.T) {
+	test.Nil(t, ShutdownCause(t.Context()))
+
+	cause := errors.New("hello")
+	test.Eq(t, cause, ShutdownCause(WithShutdownCause(t.Context(), cause)))
+}
@@ -7,8 +7,10 @@ import (
 	"github.com/spikesdivzero/launch-control/internal/lcerrors"
 )
 
+// Starts ImplRun in the background. Start may be called again once the prior ImplRun has exited, which is how the
+// controller restarts a component.
 func (c *Component) Start(ctx context.Context) error {
-	if c.doneCh != nil {
+	if c.doneCh != nil && !c.isDead() {
 		panic("Start called twice?")
 	}
 	doneCh := make(chan struct{})
@@ -17,7 +19,7 @@ func (c *Component) Start(ctx context.Context) error {
 	// The runCtx should only be used for the ImplRun call.
 	// All other cases in here should continue to use the parent context.
 	var runCtx context.Context
-	runCtx, c.runCtxCancel = context.WithCancel(ctx)
+	runCtx, c.runCtxCancel = context.WithCancelCause(ctx)
 
 	runErrCh := make(chan error, 1)
 	go func() {
@@ -52,7 +52,7 @@ func TestComponent_Start(t *testing.T) {
 
 			// Okay, it's started, and we assume the exit monitor has also started up.
 			// Let's see that runCtxCancel works (and that it's piped into ImplRun)
-			c.runCtxCancel()
+			c.runCtxCancel(nil)
 
 			synctest.Wait()
 
@@ -64,6 +64,28 @@ func TestComponent_Start(t *testing.T) {
 		})
 	})
 
+	t.Run("restart after exit", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingComponent(t)
+
+			runs := 0
+			c.ImplRun = func(ctx context.Context) error {
+				runs++
+				return nil
+			}
+			exitNotifiedCh := make(chan error, 2)
+			c.notifyOnExited = func(err error) { exitNotifiedCh <- err }
+
+			for range 2 {
+				test.NoError(t, c.Start(t.Context()))
+				synctest.Wait()
+				testutil.ChanReadIsClosed(t, c.doneCh)
+			}
+			test.Eq(t, 2, runs)
+			test.Eq(t, 2, len(exitNotifiedCh))
+		})
+	})
+
 	t.Run("prevent double call", func(t *testing.T) {
 		defer testutil.WantPanic(t, "Start called twice?")
 		c := newTestingComponent(t)
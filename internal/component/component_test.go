@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math/rand/v2"
 	"testing"
 	"time"
@@ -34,6 +35,12 @@ func newTestingComponent(*testing.T) *Component {
 	c.notifyOnExited = func(err error) {
 		panic("TestingComponent.notifyOnExited not defined but used in test")
 	}
+	c.notifyCheckReady = func(attempt int, ready bool, err error) {
+		panic("TestingComponent.notifyCheckReady not defined but used in test")
+	}
+	c.notifyShutdown = func(stage string) {
+		panic("TestingComponent.notifyShutdown not defined but used in test")
+	}
 
 	return c
 }
@@ -56,7 +63,22 @@ func TestComponent_ConnectController(t *testing.T) {
 		test.ErrorIs(t, err, testErr)
 	}
 
-	c.ConnectController(testLogError, testNotify, 242*time.Millisecond)
+	calledTestCheckReady := false
+	testCheckReady := func(attempt int, ready bool, err error) {
+		calledTestCheckReady = true
+		test.Eq(t, 3, attempt)
+		test.False(t, ready)
+		test.ErrorIs(t, err, testErr)
+	}
+
+	var gotShutdownStage string
+	testShutdown := func(stage string) { gotShutdownStage = stage }
+
+	testLog := slog.New(slog.DiscardHandler)
+
+	c.ConnectController(testLog, testLogError, testNotify, testCheckReady, testShutdown, 242*time.Millisecond)
+
+	test.Eq(t, testLog, c.log)
 
 	must.NotNil(t, c.notifyOnExited)
 	c.logError("in-test", testErr)
@@ -65,5 +87,11 @@ func TestComponent_ConnectController(t *testing.T) {
 	c.notifyOnExited(testErr)
 	test.True(t, calledTestNotify)
 
+	c.notifyCheckReady(3, false, testErr)
+	test.True(t, calledTestCheckReady)
+
+	c.notifyShutdown("impl")
+	test.Eq(t, "impl", gotShutdownStage)
+
 	test.Eq(t, 242*time.Millisecond, c.asyncGracePeriod)
 }
@@ -12,13 +12,34 @@ func (c *Component) WaitReady(ctx context.Context, abortCh <-chan struct{}) erro
 		return nil
 	}
 
-	return waitReady_MainLoop(
+	// Every attempt is reported to the controller, regardless of the outcome.
+	attempt := 0
+	checkOnce := func(ctx context.Context) (bool, error) {
+		attempt++
+		start := time.Now()
+		ready, err := c.waitReady_CheckOnce(ctx)
+		c.log.Debug("readiness check attempted", "stage", "wait-ready", "attempt", attempt, "ready", ready,
+			"duration", time.Since(start), "error", err)
+		c.notifyCheckReady(attempt, ready, err)
+		return ready, err
+	}
+	backoff := func(ctx context.Context, abortCh <-chan struct{}) error {
+		return c.waitReady_Backoff(ctx, abortCh, attempt)
+	}
+
+	start := time.Now()
+	err := waitReady_MainLoop(
 		ctx,
 		abortCh,
 		c.CheckReadyOptions.MaxAttempts,
-		c.waitReady_CheckOnce,
-		c.waitReady_Backoff,
+		checkOnce,
+		backoff,
 	)
+	if err != nil {
+		c.log.Warn("component did not become ready", "stage", "wait-ready", "attempts", attempt,
+			"duration", time.Since(start), "error", err)
+	}
+	return err
 }
 
 func waitReady_MainLoop(
@@ -60,11 +81,13 @@ func waitReady_MainLoop(
 	return lcerrors.ErrWaitReadyExceededMaxAttempts
 }
 
-func (c *Component) waitReady_Backoff(ctx context.Context, abortCh <-chan struct{}) error {
+// The attempt is the number of the attempt that just failed, for logging.
+func (c *Component) waitReady_Backoff(ctx context.Context, abortCh <-chan struct{}, attempt int) error {
 	d := c.CheckReadyOptions.Backoff()
 	if d <= 0 {
 		return nil
 	}
+	c.log.Debug("backing off before the next readiness check", "stage", "wait-ready", "attempt", attempt, "backoff", d)
 
 	select {
 	case <-time.After(d):
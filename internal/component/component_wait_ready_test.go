@@ -42,9 +42,19 @@ func TestComponent_waitReady(t *testing.T) {
 			calls = append(calls, 'b')
 			return 0
 		}
+		type attempt struct {
+			n     int
+			ready bool
+		}
+		attempts := []attempt{}
+		c.notifyCheckReady = func(n int, ready bool, err error) {
+			test.NoError(t, err)
+			attempts = append(attempts, attempt{n, ready})
+		}
 		err := c.WaitReady(t.Context(), make(chan struct{}))
 		test.ErrorIs(t, err, nil)
 		test.Eq(t, []byte("cbc"), calls)
+		test.Eq(t, []attempt{{1, false}, {2, true}}, attempts)
 	})
 }
 
@@ -275,7 +285,7 @@ func TestComponent_waitReady_Backoff(t *testing.T) {
 				}
 
 				t0 := time.Now()
-				err := c.waitReady_Backoff(ctx, abortCh)
+				err := c.waitReady_Backoff(ctx, abortCh, 1)
 				test.ErrorIs(t, err, tt.want.err)
 				test.Eq(t, tt.want.d, time.Since(t0))
 			})
@@ -16,7 +16,9 @@ type StartStopWrapper struct {
 	StopTimeout  time.Duration
 
 	stateMu       sync.Mutex
+	running       bool
 	requestStopCh chan struct{}
+	stopCause     error // from the context passed to Shutdown, see WithShutdownCause
 }
 
 func NewStartStopWrapperFor(c *Component) *StartStopWrapper {
@@ -29,25 +31,50 @@ func NewStartStopWrapperFor(c *Component) *StartStopWrapper {
 }
 
 func (ssw *StartStopWrapper) Run(ctx context.Context) error {
-	ssw.initForRun()
+	requestStopCh := ssw.initForRun()
+	defer ssw.finishRun()
 
 	if err := ssw.doCall(ctx, "StartStopWrapper.StartTimeout", ssw.StartTimeout, ssw.ImplStart); err != nil {
 		return err
 	}
 
-	<-ssw.requestStopCh
+	<-requestStopCh
 
+	ctx = WithShutdownCause(ctx, ssw.getStopCause())
 	return ssw.doCall(ctx, "StartStopWrapper.StopTimeout", ssw.StopTimeout, ssw.ImplStop)
 }
 
-func (ssw *StartStopWrapper) initForRun() {
+func (ssw *StartStopWrapper) initForRun() <-chan struct{} {
 	ssw.stateMu.Lock()
 	defer ssw.stateMu.Unlock()
 
-	if ssw.requestStopCh != nil {
+	if ssw.running {
 		panic("internal: StartStopWrapper Run called twice")
 	}
-	ssw.requestStopCh = make(chan struct{})
+	ssw.running = true
+
+	// If Shutdown was called before Run, then the channel already exists (and is closed), and we honor it.
+	if ssw.requestStopCh == nil {
+		ssw.requestStopCh = make(chan struct{})
+	}
+	return ssw.requestStopCh
+}
+
+// Resets the wrapper once Run has returned, so that it may be run again if the component is restarted.
+func (ssw *StartStopWrapper) finishRun() {
+	ssw.stateMu.Lock()
+	defer ssw.stateMu.Unlock()
+
+	ssw.running = false
+	ssw.requestStopCh = nil
+	ssw.stopCause = nil
+}
+
+func (ssw *StartStopWrapper) getStopCause() error {
+	ssw.stateMu.Lock()
+	defer ssw.stateMu.Unlock()
+
+	return ssw.stopCause
 }
 
 func (ssw *StartStopWrapper) Shutdown(ctx context.Context) error {
@@ -57,6 +84,7 @@ func (ssw *StartStopWrapper) Shutdown(ctx context.Context) error {
 	if ssw.requestStopCh == nil {
 		ssw.requestStopCh = make(chan struct{})
 	}
+	ssw.stopCause = ShutdownCause(ctx)
 	close(ssw.requestStopCh)
 	return nil
 }
@@ -26,7 +26,7 @@ func TestStartStopWrapper_Run(t *testing.T) {
 	t.Run("prevent double call", func(t *testing.T) {
 		defer testutil.WantPanic(t, "internal: StartStopWrapper Run called twice")
 		ssw := newStartStopWrapper(t)
-		ssw.requestStopCh = make(chan struct{})
+		ssw.running = true
 		_ = ssw.Run(t.Context())
 	})
 
@@ -69,6 +69,54 @@ func TestStartStopWrapper_Run(t *testing.T) {
 		test.True(t, mc.Recorder.Start.Called)
 		test.ErrorIs(t, err, mc.StartOptions.Err)
 	})
+
+	t.Run("shutdown before run", func(t *testing.T) {
+		mc := &testutil.MockComponent{}
+
+		ssw := newStartStopWrapper(t)
+		ssw.ImplStart = mc.Start
+		ssw.ImplStop = mc.Shutdown
+
+		test.NoError(t, ssw.Shutdown(t.Context()))
+		test.NoError(t, ssw.Run(t.Context())) // doesn't block
+		test.True(t, mc.Recorder.Start.Called)
+		test.True(t, mc.Recorder.Shutdown.Called)
+	})
+
+	t.Run("stop sees the shutdown cause", func(t *testing.T) {
+		cause := errors.New("stop requested")
+		var stopCause error
+		ssw := newStartStopWrapper(t)
+		ssw.ImplStart = func(context.Context) error { return nil }
+		ssw.ImplStop = func(ctx context.Context) error { stopCause = ShutdownCause(ctx); return nil }
+
+		test.NoError(t, ssw.Shutdown(WithShutdownCause(t.Context(), cause)))
+		test.NoError(t, ssw.Run(t.Context()))
+		test.Eq(t, cause, stopCause)
+		test.Nil(t, ssw.stopCause)
+	})
+
+	t.Run("can run again", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			starts := 0
+			ssw := newStartStopWrapper(t)
+			ssw.ImplStart = func(context.Context) error { starts++; return nil }
+			ssw.ImplStop = func(context.Context) error { return nil }
+
+			for range 3 {
+				resultCh := make(chan error)
+				go func() { resultCh <- ssw.Run(t.Context()) }()
+
+				synctest.Wait()
+				test.NoError(t, ssw.Shutdown(t.Context()))
+				test.NoError(t, <-resultCh)
+
+				test.False(t, ssw.running)
+				test.Nil(t, ssw.requestStopCh)
+			}
+			test.Eq(t, 3, starts)
+		})
+	})
 }
 
 func TestStartStopWrapper_Shutdown(t *testing.T) {
@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spikesdivzero/launch-control/internal/component"
+)
+
+// Components are tracked as a DAG, with edges pointing from a component to each of the components it depends on.
+//
+// A component is only started after all of its dependencies have become ready, and is only stopped after all
+// of its dependents have been stopped. Components without an explicit dependency list depend on everything that
+// was launched before them, which preserves the classic "shutdown in reverse launch order" behavior.
+type ownedComponent struct {
+	name string
+	comp Component
+
+	// The names this component explicitly depends on. Nil means "everything launched before me".
+	dependsOnNames []string
+
+	// Resolved when the launch request is processed by the control loop.
+	dependsOn []*ownedComponent
+
+	// Closed once the launch attempt has finished. If launchErr is nil at that point, the component is ready.
+	launchDoneCh chan struct{}
+	launchErr    error
+
+	// Set once Start has been called, so we know whether there's anything to shut down.
+	started bool
+
+	restart      component.RestartOptions
+	restartTimes []time.Time
+
+	// Optional components that fail are marked as such, rather than taking down the application.
+	criticality component.Criticality
+	failed      bool
+
+	// Set once the component is being stopped on purpose via StopComponent, so its exit is expected.
+	stopped bool
+
+	// See Controller.Reload.
+	reload component.ReloadOptions
+
+	// See shutdownBudget, and clDyingQuiesce.
+	shutdownReserve time.Duration
+	quiesce         func(context.Context) error
+	quiesceTimeout  time.Duration
+
+	// Reported via ComponentHandle. Running is set from just before Start until ImplRun is known to have exited.
+	state   ComponentState
+	err     error
+	running bool
+	doneCh  chan struct{}
+
+	// Reported via Status.
+	launchOrder        int
+	startedAt          time.Time
+	readyAt            time.Time
+	stoppedAt          time.Time
+	checkReadyAttempts int
+	lastErr            error
+}
+
+func newOwnedComponent(m GroupMember) *ownedComponent {
+	return &ownedComponent{
+		name:            m.Name,
+		comp:            m.Comp,
+		dependsOnNames:  m.DependsOn,
+		restart:         m.Restart,
+		criticality:     m.Criticality,
+		reload:          m.Reload,
+		shutdownReserve: m.ShutdownReserve,
+		quiesce:         m.Quiesce,
+		quiesceTimeout:  m.QuiesceTimeout,
+		launchDoneCh:    make(chan struct{}),
+		doneCh:          make(chan struct{}),
+	}
+}
+
+// Validates the members of a launch group against the names already known to the controller, returning the
+// members sorted so that dependencies always come before their dependents.
+//
+// Must be called with stateMu held.
+func (c *Controller) resolveGroup(members []GroupMember) ([]*ownedComponent, error) {
+	inGroup := map[string]*ownedComponent{}
+	for _, m := range members {
+		if _, ok := c.componentsByName[m.Name]; ok {
+			return nil, fmt.Errorf("component %q: name already in use", m.Name)
+		}
+		if _, ok := inGroup[m.Name]; ok {
+			return nil, fmt.Errorf("component %q: name used more than once in group", m.Name)
+		}
+		inGroup[m.Name] = newOwnedComponent(m)
+	}
+
+	for _, m := range members {
+		for _, dep := range m.DependsOn {
+			_, known := c.componentsByName[dep]
+			_, sibling := inGroup[dep]
+			if !known && !sibling {
+				return nil, fmt.Errorf("component %q: depends on unknown component %q", m.Name, dep)
+			}
+		}
+	}
+
+	// Depth-first topological sort over the group. Anything outside the group was launched earlier, so it can't
+	// participate in a cycle.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := map[string]int{}
+	sorted := make([]*ownedComponent, 0, len(members))
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		oc, ok := inGroup[name]
+		if !ok {
+			return nil
+		}
+		switch marks[name] {
+		case visiting:
+			cycle := append(slices.Clone(path[slices.Index(path, name):]), name)
+			return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+		case visited:
+			return nil
+		}
+
+		marks[name] = visiting
+		path = append(path, name)
+		for _, dep := range oc.dependsOnNames {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		marks[name] = visited
+
+		sorted = append(sorted, oc)
+		return nil
+	}
+
+	for _, m := range members {
+		if err := visit(m.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, oc := range sorted {
+		c.componentsByName[oc.name] = oc
+	}
+	return sorted, nil
+}
+
+// Links each member to the components it depends on. Called from the control loop, so that every earlier launch
+// request has already been added to the graph.
+//
+// Must be called with stateMu held.
+func (c *Controller) linkGroup(members []*ownedComponent) {
+	// A failed Optional component has already been dealt with, and mustn't hold up everything launched after it.
+	launchedBefore := slices.DeleteFunc(slices.Clone(c.components), func(oc *ownedComponent) bool {
+		return oc.failed && oc.criticality == component.Optional
+	})
+	for _, oc := range members {
+		if oc.dependsOnNames == nil {
+			oc.dependsOn = launchedBefore
+			continue
+		}
+		for _, name := range oc.dependsOnNames {
+			dep, ok := c.componentsByName[name]
+			if !ok {
+				dep = newStoppedPlaceholder(name)
+			}
+			oc.dependsOn = append(oc.dependsOn, dep)
+		}
+	}
+}
+
+// Returns a map of each component to the components that depend on it.
+func dependentsOf(components []*ownedComponent) map[*ownedComponent][]*ownedComponent {
+	dependents := map[*ownedComponent][]*ownedComponent{}
+	for _, oc := range components {
+		for _, dep := range oc.dependsOn {
+			dependents[dep] = append(dependents[dep], oc)
+		}
+	}
+	return dependents
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+func TestController_resolveGroup(t *testing.T) {
+	c := newTestingController(t, lifecycleAlive)
+	mc := &testutil.MockComponent{}
+
+	// Members come back in dependency order, regardless of the order they were given in.
+	group, err := c.resolveGroup([]GroupMember{
+		{Name: "api", Comp: mc, DependsOn: []string{"cache", "db"}},
+		{Name: "cache", Comp: mc, DependsOn: []string{"db"}},
+		{Name: "db", Comp: mc, DependsOn: []string{}},
+		{Name: "metrics", Comp: mc},
+	})
+	must.NoError(t, err)
+
+	names := []string{}
+	for _, oc := range group {
+		names = append(names, oc.name)
+	}
+	test.Eq(t, []string{"db", "cache", "api", "metrics"}, names)
+	test.MapLen(t, 4, c.componentsByName)
+}
+
+func TestController_linkGroup(t *testing.T) {
+	c := newTestingController(t, lifecycleAlive)
+	mc := &testutil.MockComponent{}
+
+	first, err := c.resolveGroup([]GroupMember{{Name: "a", Comp: mc}, {Name: "b", Comp: mc}})
+	must.NoError(t, err)
+	c.linkGroup(first)
+	c.components = append(c.components, first...)
+
+	second, err := c.resolveGroup([]GroupMember{
+		{Name: "implicit", Comp: mc},
+		{Name: "explicit", Comp: mc, DependsOn: []string{"b"}},
+		{Name: "root", Comp: mc, DependsOn: []string{}},
+	})
+	must.NoError(t, err)
+	c.linkGroup(second)
+
+	test.SliceEmpty(t, first[0].dependsOn)
+	test.SliceEmpty(t, first[1].dependsOn)
+
+	test.Eq(t, first, c.componentsByName["implicit"].dependsOn) // everything launched before it
+	test.Eq(t, first[1:], c.componentsByName["explicit"].dependsOn)
+	test.SliceEmpty(t, c.componentsByName["root"].dependsOn)
+
+	t.Run("dependency stopped before linking", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		group, err := c.resolveGroup([]GroupMember{{Name: "a", Comp: mc}})
+		must.NoError(t, err)
+		c.linkGroup(group)
+		c.components = append(c.components, group...)
+
+		pending, err := c.resolveGroup([]GroupMember{{Name: "b", Comp: mc, DependsOn: []string{"a"}}})
+		must.NoError(t, err)
+		c.removeComponent(group[0])
+		c.linkGroup(pending)
+
+		must.Len(t, 1, pending[0].dependsOn)
+		test.Eq(t, "a", pending[0].dependsOn[0].name)
+		test.ErrorIs(t, pending[0].dependsOn[0].launchErr, lcerrors.ErrComponentStopped)
+	})
+}
+
+func Test_dependentsOf(t *testing.T) {
+	a := newTestingOwnedComponent("a", nil)
+	b := newTestingOwnedComponent("b", nil)
+	b.dependsOn = []*ownedComponent{a}
+	c := newTestingOwnedComponent("c", nil)
+	c.dependsOn = []*ownedComponent{a, b}
+
+	got := dependentsOf([]*ownedComponent{a, b, c})
+	test.Eq(t, []*ownedComponent{b, c}, got[a])
+	test.Eq(t, []*ownedComponent{c}, got[b])
+	test.SliceEmpty(t, got[c])
+}
@@ -0,0 +1,77 @@
+package controller
+
+import "time"
+
+//go:generate go tool stringer -type ComponentState -trimprefix Component
+type ComponentState int
+
+const (
+	ComponentPending ComponentState = iota
+	ComponentStarting
+	ComponentReady
+	ComponentStopping
+	ComponentStopped
+	ComponentFailed
+	ComponentAbandoned
+)
+
+// Final states are sticky. Once a component reaches one, it's never started again.
+func (s ComponentState) isFinal() bool {
+	return s >= ComponentStopped
+}
+
+// A ComponentHandle provides a read-only view of a single launched component.
+type ComponentHandle struct {
+	c  *Controller
+	oc *ownedComponent
+}
+
+func (h *ComponentHandle) State() ComponentState {
+	h.c.stateMu.Lock()
+	defer h.c.stateMu.Unlock()
+	return h.oc.state
+}
+
+func (h *ComponentHandle) Done() <-chan struct{} {
+	return h.oc.doneCh
+}
+
+func (h *ComponentHandle) Err() error {
+	h.c.stateMu.Lock()
+	defer h.c.stateMu.Unlock()
+	return h.oc.err
+}
+
+// Moves the component to a new state, recording err if the new state is final. Transitions out of a final state
+// are ignored, so that e.g. a failed component being shut down is still reported as failed.
+//
+// Must be called with stateMu held.
+func (oc *ownedComponent) setState(state ComponentState, err error) {
+	if oc.state.isFinal() {
+		return
+	}
+	oc.state = state
+	switch state {
+	case ComponentReady:
+		oc.readyAt = time.Now()
+	case ComponentStopped, ComponentFailed, ComponentAbandoned:
+		oc.err = err
+		oc.stoppedAt = time.Now()
+	}
+	oc.maybeCloseDone()
+}
+
+// The done channel is closed once the component has reached a final state and ImplRun is no longer running.
+// Abandoned components are the exception, as we've given up on ImplRun ever returning.
+//
+// Must be called with stateMu held.
+func (oc *ownedComponent) maybeCloseDone() {
+	if !oc.state.isFinal() || (oc.running && oc.state != ComponentAbandoned) {
+		return
+	}
+	select {
+	case <-oc.doneCh:
+	default:
+		close(oc.doneCh)
+	}
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+// Silly bit to provide "coverage" for a stringer branch.
+func init() { _ = ComponentState(-1).String() }
+
+func TestComponentState_isFinal(t *testing.T) {
+	for _, s := range []ComponentState{ComponentPending, ComponentStarting, ComponentReady, ComponentStopping} {
+		test.False(t, s.isFinal(), test.Sprint(s))
+	}
+	for _, s := range []ComponentState{ComponentStopped, ComponentFailed, ComponentAbandoned} {
+		test.True(t, s.isFinal(), test.Sprint(s))
+	}
+}
+
+func TestComponentHandle(t *testing.T) {
+	c := newTestingController(t, lifecycleAlive)
+	oc := newTestingOwnedComponent("test", &testutil.MockComponent{})
+	h := &ComponentHandle{c, oc}
+
+	test.Eq(t, ComponentPending, h.State())
+	test.NoError(t, h.Err())
+	testutil.ChanReadIsBlocked(t, h.Done())
+
+	testErr := errors.New("oops")
+	oc.setState(ComponentFailed, testErr)
+	test.Eq(t, ComponentFailed, h.State())
+	test.ErrorIs(t, h.Err(), testErr)
+	testutil.ChanReadIsClosed(t, h.Done())
+}
+
+func TestOwnedComponent_setState(t *testing.T) {
+	testErr := errors.New("oops")
+
+	t.Run("not final", func(t *testing.T) {
+		oc := newTestingOwnedComponent("test", &testutil.MockComponent{})
+		oc.setState(ComponentStarting, testErr)
+		test.Eq(t, ComponentStarting, oc.state)
+		test.NoError(t, oc.err) // only recorded for final states
+		testutil.ChanReadIsBlocked(t, oc.doneCh)
+	})
+
+	t.Run("final is sticky", func(t *testing.T) {
+		oc := newTestingOwnedComponent("test", &testutil.MockComponent{})
+		oc.setState(ComponentFailed, testErr)
+		oc.setState(ComponentStopping, nil)
+		oc.setState(ComponentStopped, nil)
+		test.Eq(t, ComponentFailed, oc.state)
+		test.ErrorIs(t, oc.err, testErr)
+		testutil.ChanReadIsClosed(t, oc.doneCh)
+	})
+
+	t.Run("done waits for exit", func(t *testing.T) {
+		oc := newTestingOwnedComponent("test", &testutil.MockComponent{})
+		oc.running = true
+		oc.setState(ComponentFailed, testErr)
+		testutil.ChanReadIsBlocked(t, oc.doneCh)
+
+		oc.running = false
+		oc.maybeCloseDone()
+		testutil.ChanReadIsClosed(t, oc.doneCh)
+		oc.maybeCloseDone() // closing twice is fine
+	})
+
+	t.Run("abandoned doesn't wait for exit", func(t *testing.T) {
+		oc := newTestingOwnedComponent("test", &testutil.MockComponent{})
+		oc.running = true
+		oc.setState(ComponentAbandoned, testErr)
+		testutil.ChanReadIsClosed(t, oc.doneCh)
+	})
+}
@@ -16,9 +16,8 @@ const (
 )
 
 type launchRequest struct {
-	name   string
-	comp   Component
-	doneCh chan struct{}
+	members []*ownedComponent
+	doneCh  chan struct{}
 }
 
 // The main entry point for our controlLoop. It's job is just to call the different lifecycle stages in order.
@@ -34,6 +33,12 @@ func (c *Controller) controlLoop() {
 	c.controlLoop_Dying()
 
 	c.clSetState(lifecycleDying, lifecycleDead)
+	defer func() {
+		c.stateMu.Lock()
+		defer c.stateMu.Unlock()
+		c.logStopped(c.firstError())
+		c.stopWatchingParentLocked()
+	}()
 
 	// In some cases, and especially when exercised by the race detector, the doneCh is closed before all the
 	// monitorExit coroutines had a chance to report their final status back to the controller.
@@ -69,4 +74,6 @@ func (c *Controller) clSetState(from, to lifecycleState) {
 		panic(fmt.Sprintf("internal: SetState from state %v, expected %v", c.lifecycleState, from))
 	}
 	c.lifecycleState = to
+	c.emitStateChange(from, to)
+	c.Log.Info("controller state changed", "from", from.String(), "to", to.String())
 }
@@ -1,26 +1,65 @@
 package controller
 
+import (
+	"sync"
+	"time"
+
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
+
 // The contents of this file run when lifecycleState is lifecycleAlive.
 //
 // I've split it into different files based on stages both for consistency with the component code,
-// as well as clarity in the event that I need to extend this. (For instance, I may in the future
-// add in the ability to launch a group of components concurrently.)
-
+// as well as clarity in the event that I need to extend this.
 func (c *Controller) controlLoop_Alive() {
 	c.clAssertState("controlLoop_Alive", lifecycleAlive)
 
+	// Every request is marked as keeping us busy, so that the watchdog can tell a long launch or reload apart from a
+	// stalled control loop (see controlLoopAlive).
+	defer c.controlLoopBusy.Store(false)
 	for {
+		c.controlLoopBusy.Store(false)
 		select {
 		case <-c.requestStopCh:
 			return
 		case req := <-c.requestLaunchCh:
+			c.controlLoopBusy.Store(true)
 			c.clAliveDoLaunch(req)
+		case resultCh := <-c.requestFinishStartupCh:
+			c.controlLoopBusy.Store(true)
+			c.clAliveDoFinishStartup(resultCh)
+		case oc := <-c.requestRestartCh:
+			c.controlLoopBusy.Store(true)
+			c.clAliveDoRestart(oc)
+		case req := <-c.requestStopComponentCh:
+			c.controlLoopBusy.Store(true)
+			c.clAliveDoStopComponent(req)
+		case req := <-c.requestReplaceCh:
+			c.controlLoopBusy.Store(true)
+			c.clAliveDoReplace(req)
+		case req := <-c.requestReloadCh:
+			c.controlLoopBusy.Store(true)
+			c.clAliveDoReload(req)
+		case <-c.watchdogPingCh:
+			// Nothing to do; being picked up at all shows that we're responsive.
 		}
 	}
 }
 
+func (c *Controller) clAliveDoFinishStartup(resultCh chan error) {
+	// Same reasoning as in clAliveDoLaunch.
+	if c.isStopRequested() {
+		resultCh <- lcerrors.ErrControllerNotAlive
+		return
+	}
+
+	if c.finishStartup() && c.OnStartupFinished != nil {
+		c.OnStartupFinished()
+	}
+	resultCh <- nil
+}
+
 func (c *Controller) clAliveDoLaunch(req launchRequest) {
-	defer close(req.doneCh)
 
 	// Up in the controlLoop_Alive select, we're doing a two-case channel read.
 	//
@@ -31,24 +70,126 @@ func (c *Controller) clAliveDoLaunch(req launchRequest) {
 	// we're supposed to be dying.
 	select {
 	case <-c.requestStopCh:
+		c.discardLaunch(req)
 		return
 	default:
 	}
+	defer close(req.doneCh)
 
 	// Even if Start() returned an error, it's possible that ImplRun has been started up. Accordingly, when we
 	// do our shutdown process, we want to shutdown this component as well.
 	c.stateMu.Lock()
-	c.components = append(c.components, ownedComponent{req.name, req.comp})
+	c.linkGroup(req.members)
+	for _, oc := range req.members {
+		c.launchCount++
+		oc.launchOrder = c.launchCount
+	}
+	c.components = append(c.components, req.members...)
+	c.stateMu.Unlock()
+
+	// Each member of a group is started as soon as its dependencies are ready, and we wait for the slowest.
+	//
+	// If any member fails, it requests a stop, which also aborts any siblings still waiting to launch. The
+	// members that did start are shut down during controlLoop_Dying.
+	var wg sync.WaitGroup
+	for _, oc := range req.members {
+		wg.Go(func() {
+			defer close(oc.launchDoneCh)
+			oc.launchErr = c.clAliveDoLaunchOne(oc)
+		})
+	}
+	wg.Wait()
+}
+
+func (c *Controller) clAliveDoLaunchOne(oc *ownedComponent) error {
+	for _, dep := range oc.dependsOn {
+		select {
+		case <-dep.launchDoneCh:
+		case <-c.requestStopCh:
+			return lcerrors.ErrWaitReadyAbortChClosed
+		}
+
+		if dep.launchErr == nil {
+			continue
+		}
+
+		// A critical dependency failing has already requested a stop, so there's nothing more to report. An optional
+		// one failing means that we can't launch either.
+		if c.isStopRequested() {
+			return lcerrors.ErrWaitReadyAbortChClosed
+		}
+		err := lcerrors.DependencyError{Name: dep.name, Err: lcerrors.ErrDependencyNotReady}
+		c.failComponent(oc, "startup", err, false)
+		return err
+	}
+
+	c.stateMu.Lock()
+	oc.started = true
+	oc.setState(ComponentStarting, nil)
 	c.stateMu.Unlock()
 
-	if err := req.comp.Start(c.ctx); err != nil {
-		c.recordComponentError(req.name, "startup", err)
-		c.RequestStop(nil)
+	return c.clAliveStartAndWait(oc)
+}
+
+func (c *Controller) clAliveStartAndWait(oc *ownedComponent) error {
+	c.stateMu.Lock()
+	oc.running = true
+	oc.startedAt = time.Now()
+	oc.readyAt = time.Time{}
+	c.stateMu.Unlock()
+
+	log := c.componentLog(oc)
+	log.Info("starting component", "stage", "startup")
+
+	if err := oc.comp.Start(c.ctx); err != nil {
+		c.failComponent(oc, "startup", err, true)
+		return err
+	}
+	c.emit(Event{Kind: EventComponentStarted, Component: oc.name})
+
+	if err := oc.comp.WaitReady(c.ctx, c.requestStopCh); err != nil {
+		c.failComponent(oc, "wait-ready", err, true)
+		return err
+	}
+
+	c.stateMu.Lock()
+	oc.setState(ComponentReady, nil)
+	duration := oc.readyAt.Sub(oc.startedAt)
+	c.stateMu.Unlock()
+	c.emit(Event{Kind: EventComponentReady, Component: oc.name})
+	log.Info("component ready", "stage", "wait-ready", "duration", duration)
+	return nil
+}
+
+// Discards a launch request that arrived too late, marking its members as stopped.
+func (c *Controller) discardLaunch(req launchRequest) {
+	c.stateMu.Lock()
+	for _, oc := range req.members {
+		oc.setState(ComponentStopped, nil)
+	}
+	c.stateMu.Unlock()
+
+	close(req.doneCh)
+}
+
+// Restarts a component whose ImplRun has exited, as allowed by its restart policy.
+//
+// Failing to start or become ready again is handled the same as a failed launch.
+func (c *Controller) clAliveDoRestart(oc *ownedComponent) {
+	// Same reasoning as in clAliveDoLaunch.
+	select {
+	case <-c.requestStopCh:
 		return
+	default:
 	}
 
-	if err := req.comp.WaitReady(c.ctx, c.requestStopCh); err != nil {
-		c.recordComponentError(req.name, "wait-ready", err)
-		c.RequestStop(nil)
+	// The component may have been stopped while the restart was pending.
+	c.stateMu.Lock()
+	stopped := oc.stopped
+	c.stateMu.Unlock()
+	if stopped {
+		return
 	}
+
+	_ = c.clAliveStartAndWait(oc)
 }
@@ -2,10 +2,15 @@ package controller
 
 import (
 	"errors"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"testing/synctest"
+	"time"
 
 	"github.com/shoenig/test"
+	"github.com/spikesdivzero/launch-control/internal/component"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
 	"github.com/spikesdivzero/launch-control/internal/testutil"
 )
 
@@ -32,7 +37,7 @@ func TestController_controlLoop_Alive(t *testing.T) {
 			for range 8 {
 				mc := &testutil.MockComponent{}
 				doneCh := make(chan struct{})
-				c.requestLaunchCh <- launchRequest{"test", mc, doneCh}
+				c.requestLaunchCh <- launchRequest{[]*ownedComponent{newTestingOwnedComponent("test", mc)}, doneCh}
 
 				synctest.Wait()
 				testutil.ChanReadIsClosed(t, doneCh)    // finished
@@ -44,12 +49,75 @@ func TestController_controlLoop_Alive(t *testing.T) {
 			testutil.ChanReadIsClosed(t, clExited) // responded
 		})
 	})
+
+	t.Run("finishes startup", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			var hookCalls atomic.Int32
+			c.OnStartupFinished = func() { hookCalls.Add(1) }
+
+			clExited := make(chan struct{})
+			go func() {
+				defer close(clExited)
+				c.controlLoop_Alive()
+			}()
+
+			launch := func() {
+				mc := &testutil.MockComponent{}
+				doneCh := make(chan struct{})
+				c.requestLaunchCh <- launchRequest{[]*ownedComponent{newTestingOwnedComponent("test", mc)}, doneCh}
+				<-doneCh
+			}
+
+			// However long it takes, startup doesn't finish on its own.
+			launch()
+			time.Sleep(time.Hour)
+			launch()
+			time.Sleep(time.Hour)
+			test.False(t, c.Status().StartupFinished)
+			test.Eq(t, 0, hookCalls.Load())
+
+			test.NoError(t, c.FinishStartup())
+			test.True(t, c.Status().StartupFinished)
+			test.Eq(t, 1, hookCalls.Load())
+
+			// Only once.
+			test.NoError(t, c.FinishStartup())
+			launch()
+			test.Eq(t, 1, hookCalls.Load())
+
+			close(c.requestStopCh)
+			<-clExited
+		})
+	})
+
+	t.Run("not before launching", func(t *testing.T) {
+		c := newTestingController(t, lifecycleNew)
+		test.ErrorIs(t, c.FinishStartup(), lcerrors.ErrControllerNotAlive)
+	})
+
+	t.Run("not once stopping", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			c.OnStartupFinished = func() { t.Error("unexpected call") }
+
+			go c.controlLoop_Alive()
+			doneCh := make(chan struct{})
+			mc := &testutil.MockComponent{}
+			c.requestLaunchCh <- launchRequest{[]*ownedComponent{newTestingOwnedComponent("test", mc)}, doneCh}
+			<-doneCh
+			c.RequestStop(nil)
+
+			test.ErrorIs(t, c.FinishStartup(), lcerrors.ErrControllerNotAlive)
+			test.False(t, c.Status().StartupFinished)
+		})
+	})
 }
 
 func TestController_clAliveDoLaunch(t *testing.T) {
 	makeReq := func() (*testutil.MockComponent, launchRequest) {
 		mc := &testutil.MockComponent{}
-		return mc, launchRequest{"test", mc, make(chan struct{})}
+		return mc, launchRequest{[]*ownedComponent{newTestingOwnedComponent("test", mc)}, make(chan struct{})}
 	}
 
 	t.Run("discard when stop requested", func(t *testing.T) {
@@ -73,8 +141,7 @@ func TestController_clAliveDoLaunch(t *testing.T) {
 		test.True(t, mc.Recorder.Start.Called)
 		test.True(t, mc.Recorder.WaitReady.Called)
 		test.Len(t, 1, c.components)
-		test.Eq(t, req.name, c.components[0].name)
-		test.Eq(t, mc, c.components[0].comp.(*testutil.MockComponent))
+		test.Eq(t, req.members, c.components)
 
 		test.Eq(t, c.requestStopCh, mc.Recorder.WaitReady.AbortLoopCh)
 
@@ -93,8 +160,7 @@ func TestController_clAliveDoLaunch(t *testing.T) {
 		test.True(t, mc.Recorder.Start.Called)
 		test.False(t, mc.Recorder.WaitReady.Called)
 		test.Len(t, 1, c.components)
-		test.Eq(t, req.name, c.components[0].name)
-		test.Eq(t, mc, c.components[0].comp.(*testutil.MockComponent))
+		test.Eq(t, req.members, c.components)
 
 		testutil.ChanReadIsClosed(t, c.requestStopCh)
 		test.ErrorIs(t, c.Err(), testErr)
@@ -112,10 +178,145 @@ func TestController_clAliveDoLaunch(t *testing.T) {
 		test.True(t, mc.Recorder.Start.Called)
 		test.True(t, mc.Recorder.WaitReady.Called)
 		test.Len(t, 1, c.components)
-		test.Eq(t, req.name, c.components[0].name)
-		test.Eq(t, mc, c.components[0].comp.(*testutil.MockComponent))
+		test.Eq(t, req.members, c.components)
 
 		testutil.ChanReadIsClosed(t, c.requestStopCh)
 		test.ErrorIs(t, c.Err(), testErr)
 	})
 }
+
+func TestController_clAliveDoLaunch_Group(t *testing.T) {
+	makeGroupReq := func(n int) ([]*testutil.MockComponent, launchRequest) {
+		mcs := make([]*testutil.MockComponent, n)
+		req := launchRequest{doneCh: make(chan struct{})}
+		for i := range mcs {
+			mcs[i] = &testutil.MockComponent{}
+			req.members = append(req.members, newTestingOwnedComponent(fmt.Sprintf("test-%d", i), mcs[i]))
+		}
+		return mcs, req
+	}
+
+	t.Run("members launch concurrently", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			mcs, req := makeGroupReq(3)
+			for i, mc := range mcs {
+				mc.WaitReadyOptions.Sleep = time.Duration(i+1) * time.Second
+			}
+
+			t0 := time.Now()
+			c.clAliveDoLaunch(req)
+			test.Eq(t, 3*time.Second, time.Since(t0)) // slowest member, not the sum
+
+			testutil.ChanReadIsClosed(t, req.doneCh)
+			for _, mc := range mcs {
+				test.True(t, mc.Recorder.Start.Called)
+				test.True(t, mc.Recorder.WaitReady.Called)
+			}
+			test.Eq(t, req.members, c.components)
+			testutil.ChanReadIsBlocked(t, c.requestStopCh)
+		})
+	})
+
+	t.Run("members wait on their dependencies", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			mcs, req := makeGroupReq(3)
+			for i, mc := range mcs {
+				mc.WaitReadyOptions.Sleep = time.Duration(i+1) * time.Second
+			}
+
+			// test-2 depends on test-0, test-1 depends on test-2.
+			req.members[2].dependsOnNames = []string{"test-0"}
+			req.members[1].dependsOnNames = []string{"test-2"}
+			for _, oc := range req.members {
+				c.componentsByName[oc.name] = oc
+			}
+
+			startTimes := make([]time.Duration, 3)
+			t0 := time.Now()
+			for i, mc := range mcs {
+				mc.StartOptions.Hook = func() { startTimes[i] = time.Since(t0) }
+			}
+
+			c.clAliveDoLaunch(req)
+			test.Eq(t, []time.Duration{0, 4 * time.Second, time.Second}, startTimes)
+			test.Eq(t, 6*time.Second, time.Since(t0))
+			testutil.ChanReadIsBlocked(t, c.requestStopCh)
+		})
+	})
+
+	t.Run("optional dependency fails", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			mcs, req := makeGroupReq(3)
+			mcs[0].WaitReadyOptions.Err = errors.New("not today")
+			req.members[0].criticality = component.Optional
+			req.members[1].dependsOnNames = []string{"test-0"}
+			req.members[1].criticality = component.Optional
+			req.members[2].dependsOnNames = []string{}
+			for _, oc := range req.members {
+				c.componentsByName[oc.name] = oc
+			}
+
+			c.clAliveDoLaunch(req)
+			testutil.ChanReadIsBlocked(t, c.requestStopCh) // the app carries on
+			test.True(t, req.members[0].failed)
+			test.True(t, req.members[1].failed)
+			test.False(t, mcs[1].Recorder.Start.Called)
+			test.False(t, req.members[2].failed)
+			test.Eq(t, ComponentFailed, req.members[1].state)
+			testutil.ChanReadIsClosed(t, req.members[1].doneCh) // never started, so nothing to wait for
+			test.Eq(t, ComponentReady, req.members[2].state)
+			test.Eq(t, []error{
+				lcerrors.ComponentError{Name: "test-0", Stage: "wait-ready", Err: mcs[0].WaitReadyOptions.Err},
+				lcerrors.ComponentError{Name: "test-1", Stage: "startup", Err: lcerrors.DependencyError{
+					Name: "test-0",
+					Err:  lcerrors.ErrDependencyNotReady,
+				}},
+			}, c.AllErrors())
+		})
+	})
+
+	t.Run("dependency fails", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			mcs, req := makeGroupReq(2)
+			mcs[0].WaitReadyOptions.Err = errors.New("not today")
+			req.members[1].dependsOnNames = []string{"test-0"}
+			for _, oc := range req.members {
+				c.componentsByName[oc.name] = oc
+			}
+
+			c.clAliveDoLaunch(req)
+			test.False(t, mcs[1].Recorder.Start.Called)
+			test.False(t, req.members[1].started)
+			test.Error(t, req.members[1].launchErr)
+			testutil.ChanReadIsClosed(t, c.requestStopCh)
+		})
+	})
+
+	t.Run("one member fails", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			mcs, req := makeGroupReq(3)
+
+			testErr := errors.New("nope")
+			mcs[1].StartOptions.Err = testErr
+			mcs[1].StartOptions.Sleep = time.Second
+
+			// A sibling waiting to become ready should be aborted by the failed launch.
+			mcs[2].WaitReadyOptions.Hook = func() { <-c.requestStopCh }
+
+			t0 := time.Now()
+			c.clAliveDoLaunch(req)
+			test.Eq(t, time.Second, time.Since(t0))
+
+			testutil.ChanReadIsClosed(t, req.doneCh)
+			test.False(t, mcs[1].Recorder.WaitReady.Called)
+			test.Len(t, 3, c.components) // the whole group gets shut down
+			testutil.ChanReadIsClosed(t, c.requestStopCh)
+			test.ErrorIs(t, c.Err(), testErr)
+		})
+	})
+}
@@ -1,6 +1,13 @@
 package controller
 
-import "slices"
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/spikesdivzero/launch-control/internal/component"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
 
 // The contents of this file run when lifecycleState is lifecycleDying.
 
@@ -10,17 +17,135 @@ func (c *Controller) controlLoop_Dying() {
 	// All outstanding launch requests must be summarily discarded.
 	close(c.requestLaunchCh)
 	for req := range c.requestLaunchCh {
-		close(req.doneCh)
+		c.discardLaunch(req)
+	}
+
+	// Run the graceful shutdown procedure, walking the component graph in reverse topological order.
+	//
+	// Each component is stopped only once all of its dependents have been stopped. Components that don't depend on
+	// each other (e.g. members of a launch group) are stopped in parallel.
+	//
+	// The components' shutdowns see the reason for it, via the context.
+	ctx := c.ctx
+	if reason, _ := c.ShutdownReason(); c.NewShutdownCause != nil {
+		ctx = component.WithShutdownCause(ctx, c.NewShutdownCause(reason))
+	}
+
+	// If there's a ShutdownTimeout, each component's shutdown is limited to its share of it.
+	budget := newShutdownBudget(c.ShutdownTimeout, c.components)
+
+	// Before anything is shut down, every component gets the chance to stop taking on new work, all at once.
+	c.clDyingQuiesce(ctx, budget)
+
+	// A component being stopped via StopComponent is no longer in the graph, but the components it depends on must
+	// still outlive it.
+	c.componentStops.Wait()
+
+	dependents := dependentsOf(c.components)
+	stoppedChs := map[*ownedComponent]chan struct{}{}
+	for _, oc := range c.components {
+		stoppedChs[oc] = make(chan struct{})
 	}
 
-	// Run the graceful shutdown procedure (stopping all components in the reverse order of when they were started).
-	for _, comp := range slices.Backward(c.components) {
-		c.clDyingDoShutdown(comp)
+	var wg sync.WaitGroup
+	for _, oc := range c.components {
+		wg.Go(func() {
+			defer close(stoppedChs[oc])
+			for _, dependent := range dependents[oc] {
+				<-stoppedChs[dependent]
+			}
+			c.clDyingDoShutdown(ctx, budget, oc)
+		})
 	}
+	wg.Wait()
 }
 
-func (c *Controller) clDyingDoShutdown(oc ownedComponent) {
-	if err := oc.comp.Shutdown(c.ctx); err != nil {
-		c.recordComponentError(oc.name, "shutdown", err)
+func (c *Controller) clDyingDoShutdown(ctx context.Context, budget *shutdownBudget, oc *ownedComponent) {
+	d, limited := budget.take(oc)
+
+	// A component may never have been started, if it was still waiting on its dependencies when the stop came in.
+	// Failed (optional) components have already been cleaned up.
+	c.stateMu.Lock()
+	skip := !oc.started || oc.failed
+	if !oc.started {
+		oc.setState(ComponentStopped, nil)
+	}
+	running := oc.running
+	c.stateMu.Unlock()
+	if skip {
+		return
+	}
+
+	if limited {
+		// Nothing can be done in the time left, so the component is left running, the same as if it didn't respond
+		// to its shutdown.
+		if d <= 0 && running {
+			c.stateMu.Lock()
+			oc.setState(ComponentAbandoned, lcerrors.ErrShutdownBudgetExhausted)
+			c.stateMu.Unlock()
+			c.recordComponentError(oc, "shutdown", lcerrors.ErrShutdownBudgetExhausted)
+			return
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, max(d, 0),
+			lcerrors.ContextTimeoutError{Source: "ControllerShutdownTimeout"})
+		defer cancel()
+	}
+
+	if err := c.shutdownComponent(ctx, oc); err != nil {
+		c.recordComponentError(oc, "shutdown", err)
+	}
+}
+
+// Calls the quiesce function of every running component in parallel, returning once all of them have returned (or
+// were abandoned, if they didn't respond to their own timeout, or to the budget running out).
+func (c *Controller) clDyingQuiesce(ctx context.Context, budget *shutdownBudget) {
+	c.stateMu.Lock()
+	var quiescing []*ownedComponent
+	for _, oc := range c.components {
+		if oc.quiesce != nil && oc.running && !oc.failed {
+			quiescing = append(quiescing, oc)
+		}
+	}
+	c.stateMu.Unlock()
+	if len(quiescing) == 0 {
+		return
+	}
+
+	// The reserves are kept for the shutdowns, same as with the components stopped early on.
+	if d, limited := budget.remaining(); limited {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, max(d, 0),
+			lcerrors.ContextTimeoutError{Source: "ControllerShutdownTimeout"})
+		defer cancel()
+	}
+
+	var wg sync.WaitGroup
+	for _, oc := range quiescing {
+		wg.Go(func() { c.quiesceComponent(ctx, oc) })
+	}
+	wg.Wait()
+}
+
+func (c *Controller) quiesceComponent(ctx context.Context, oc *ownedComponent) {
+	c.onShutdownStage(oc, "quiesce")
+	log := c.componentLog(oc)
+	log.Info("quiescing component")
+	start := time.Now()
+
+	timeout := oc.quiesceTimeout
+	if timeout <= 0 {
+		timeout = component.NoTimeout
+	}
+	resultCh := component.AsyncCall(ctx, "Quiesce.Timeout", timeout, c.AsyncGracePeriod, oc.quiesce)
+	err, callErr := (<-resultCh).Values()
+	if callErr != nil {
+		err = callErr
+	}
+	if err != nil {
+		c.recordComponentError(oc, "quiesce", err)
+		return
 	}
+	log.Debug("component quiesced", "duration", time.Since(start))
 }
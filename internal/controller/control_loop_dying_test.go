@@ -1,10 +1,14 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"slices"
+	"sync"
 	"testing"
+	"testing/synctest"
+	"time"
 
 	"github.com/shoenig/test"
 	"github.com/spikesdivzero/launch-control/internal/lcerrors"
@@ -26,7 +30,7 @@ func TestController_controlLoop_Dying(t *testing.T) {
 		test.GreaterEq(t, 8, cap(c.requestLaunchCh))
 		reqs := make([]launchRequest, cap(c.requestLaunchCh))
 		for i := range reqs {
-			reqs[i] = launchRequest{"test", nocallMc, make(chan struct{})}
+			reqs[i] = launchRequest{[]*ownedComponent{newTestingOwnedComponent("test", nocallMc)}, make(chan struct{})}
 			c.requestLaunchCh <- reqs[i]
 		}
 
@@ -40,7 +44,9 @@ func TestController_controlLoop_Dying(t *testing.T) {
 			mc := &testutil.MockComponent{}
 			mc.ShutdownOptions.Hook = func() { gotShutdownOrder = append(gotShutdownOrder, name) }
 
-			c.components = append(c.components, ownedComponent{name, mc})
+			oc := newStartedOwnedComponent(name, mc)
+			oc.dependsOn = slices.Clone(c.components) // as linkGroup would
+			c.components = append(c.components, oc)
 		}
 
 		// Now let it run
@@ -50,6 +56,7 @@ func TestController_controlLoop_Dying(t *testing.T) {
 		testutil.ChanReadIsClosed(t, c.requestLaunchCh) // everything abandoned
 		for _, req := range reqs {
 			testutil.ChanReadIsClosed(t, req.doneCh) // all unblocked
+			test.Eq(t, ComponentStopped, req.members[0].state)
 		}
 
 		// And check our shutdown order
@@ -62,7 +69,7 @@ func TestController_clDyingDoShutdown(t *testing.T) {
 	t.Run("happy", func(t *testing.T) {
 		c := newTestingController(t, lifecycleDying)
 		mc := &testutil.MockComponent{}
-		c.clDyingDoShutdown(ownedComponent{"test-comp", mc})
+		c.clDyingDoShutdown(t.Context(), &shutdownBudget{}, newStartedOwnedComponent("test-comp", mc))
 		test.True(t, mc.Recorder.Shutdown.Called)
 	})
 
@@ -70,7 +77,7 @@ func TestController_clDyingDoShutdown(t *testing.T) {
 		c := newTestingController(t, lifecycleDying)
 		mc := &testutil.MockComponent{}
 		mc.ShutdownOptions.Err = errors.New("test error")
-		c.clDyingDoShutdown(ownedComponent{"test-comp", mc})
+		c.clDyingDoShutdown(t.Context(), &shutdownBudget{}, newStartedOwnedComponent("test-comp", mc))
 		test.True(t, mc.Recorder.Shutdown.Called)
 		test.ErrorIs(t, c.Err(), lcerrors.ComponentError{
 			Name:  "test-comp",
@@ -79,3 +86,227 @@ func TestController_clDyingDoShutdown(t *testing.T) {
 		})
 	})
 }
+
+func TestController_controlLoop_Dying_Graph(t *testing.T) {
+	t.Run("independent components stop in parallel", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleDying)
+
+			mcs := []*testutil.MockComponent{}
+			for i := range 3 {
+				mc := &testutil.MockComponent{}
+				mc.ShutdownOptions.Sleep = time.Duration(i+1) * time.Second
+				if i == 1 {
+					mc.ShutdownOptions.Err = errors.New("test error")
+				}
+				mcs = append(mcs, mc)
+				c.components = append(c.components, newStartedOwnedComponent(fmt.Sprintf("comp-%v", i), mc))
+			}
+
+			t0 := time.Now()
+			c.controlLoop_Dying()
+			test.Eq(t, 3*time.Second, time.Since(t0))
+
+			for _, mc := range mcs {
+				test.True(t, mc.Recorder.Shutdown.Called)
+			}
+			test.ErrorIs(t, c.Err(), lcerrors.ComponentError{
+				Name:  "comp-1",
+				Stage: "shutdown",
+				Err:   mcs[1].ShutdownOptions.Err,
+			})
+		})
+	})
+
+	t.Run("dependents stop first", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleDying)
+
+			// A diamond: db <- (cache, queue) <- api
+			var mu sync.Mutex
+			stopTimes := map[string]time.Duration{}
+			t0 := time.Now()
+			add := func(name string, sleep time.Duration, deps ...*ownedComponent) *ownedComponent {
+				mc := &testutil.MockComponent{}
+				mc.ShutdownOptions.Sleep = sleep
+				mc.ShutdownOptions.Hook = func() {
+					mu.Lock()
+					defer mu.Unlock()
+					stopTimes[name] = time.Since(t0)
+				}
+				oc := newStartedOwnedComponent(name, mc)
+				oc.dependsOn = deps
+				c.components = append(c.components, oc)
+				return oc
+			}
+
+			db := add("db", time.Second)
+			cache := add("cache", time.Second, db)
+			queue := add("queue", 3*time.Second, db)
+			add("api", 2*time.Second, cache, queue)
+
+			c.controlLoop_Dying()
+			test.Eq(t, map[string]time.Duration{
+				"api":   0,
+				"cache": 2 * time.Second,
+				"queue": 2 * time.Second,
+				"db":    5 * time.Second, // waits for the slower of cache and queue
+			}, stopTimes)
+		})
+	})
+
+	t.Run("skips components that never started", func(t *testing.T) {
+		c := newTestingController(t, lifecycleDying)
+		mc := &testutil.MockComponent{}
+		c.components = append(c.components, newTestingOwnedComponent("never-started", mc))
+
+		c.controlLoop_Dying()
+		test.False(t, mc.Recorder.Shutdown.Called)
+	})
+
+	t.Run("skips failed components", func(t *testing.T) {
+		c := newTestingController(t, lifecycleDying)
+		mc := &testutil.MockComponent{}
+		oc := newStartedOwnedComponent("failed", mc)
+		oc.failed = true
+		c.components = append(c.components, oc)
+
+		c.controlLoop_Dying()
+		test.False(t, mc.Recorder.Shutdown.Called)
+	})
+}
+
+func TestController_controlLoop_Dying_ShutdownTimeout(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := newTestingController(t, lifecycleDying)
+		c.ShutdownTimeout = 10 * time.Second
+
+		// Launched first, so stopped last, and its reserve can't be used up by the others.
+		flusherMc := &testutil.MockComponent{}
+		flusher := newStartedOwnedComponent("flusher", flusherMc)
+		flusher.shutdownReserve = 3 * time.Second
+
+		// Gets nothing, as the slow component uses up everything but the flusher's reserve.
+		skippedMc := &testutil.MockComponent{}
+		skipped := newStartedOwnedComponent("skipped", skippedMc)
+
+		slowMc := &testutil.MockComponent{}
+		slowMc.ShutdownOptions.Sleep = 7 * time.Second
+		slow := newStartedOwnedComponent("slow", slowMc)
+
+		for _, oc := range []*ownedComponent{flusher, skipped, slow} {
+			oc.dependsOn = slices.Clone(c.components)
+			c.components = append(c.components, oc)
+		}
+
+		var slowD, flusherD time.Duration
+		slowMc.ShutdownOptions.Hook = func() {
+			deadline, _ := slowMc.Recorder.Shutdown.Ctx.Deadline()
+			slowD = time.Until(deadline)
+		}
+		flusherMc.ShutdownOptions.Hook = func() {
+			deadline, _ := flusherMc.Recorder.Shutdown.Ctx.Deadline()
+			flusherD = time.Until(deadline)
+		}
+
+		c.controlLoop_Dying()
+
+		test.Eq(t, 7*time.Second, slowD)
+		test.Eq(t, 3*time.Second, flusherD)
+
+		test.False(t, skippedMc.Recorder.Shutdown.Called)
+		test.Eq(t, ComponentAbandoned, skipped.state)
+		test.ErrorIs(t, c.Err(), lcerrors.ComponentError{
+			Name:  "skipped",
+			Stage: "shutdown",
+			Err:   lcerrors.ErrShutdownBudgetExhausted,
+		})
+	})
+}
+
+func TestController_clDyingQuiesce(t *testing.T) {
+	t.Run("in parallel, before any shutdown", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleDying)
+			t0 := time.Now()
+
+			var mu sync.Mutex
+			var events []string
+			record := func(event string) {
+				mu.Lock()
+				defer mu.Unlock()
+				events = append(events, fmt.Sprintf("%v %v", time.Since(t0), event))
+			}
+
+			for _, name := range []string{"db", "http"} {
+				mc := &testutil.MockComponent{}
+				mc.ShutdownOptions.Hook = func() { record(name + " shutdown") }
+				oc := newStartedOwnedComponent(name, mc)
+				oc.quiesce = func(context.Context) error {
+					time.Sleep(time.Second)
+					record(name + " quiesced")
+					return nil
+				}
+				oc.dependsOn = slices.Clone(c.components)
+				c.components = append(c.components, oc)
+			}
+
+			// Not running, so there's nothing to quiesce.
+			idle := newTestingOwnedComponent("idle", &testutil.MockComponent{})
+			idle.quiesce = func(context.Context) error { panic("quiesce called on a component that isn't running") }
+			c.components = append(c.components, idle)
+
+			c.controlLoop_Dying()
+			test.SliceContainsAll(t, []string{"1s db quiesced", "1s http quiesced"}, events[:2])
+			test.Eq(t, []string{"1s http shutdown", "1s db shutdown"}, events[2:])
+		})
+	})
+
+	t.Run("records errors", func(t *testing.T) {
+		c := newTestingController(t, lifecycleDying)
+		oc := newStartedOwnedComponent("test", &testutil.MockComponent{})
+		err := errors.New("quiesce failed")
+		oc.quiesce = func(context.Context) error { return err }
+		c.components = append(c.components, oc)
+
+		c.clDyingQuiesce(t.Context(), &shutdownBudget{})
+		test.ErrorIs(t, c.Err(), lcerrors.ComponentError{Name: "test", Stage: "quiesce", Err: err})
+	})
+
+	t.Run("limited by the budget", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleDying)
+			oc := newStartedOwnedComponent("test", &testutil.MockComponent{})
+			oc.shutdownReserve = 2 * time.Second
+			oc.quiesce = func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}
+			c.components = append(c.components, oc)
+
+			start := time.Now()
+			c.clDyingQuiesce(t.Context(), newShutdownBudget(5*time.Second, c.components))
+			test.Eq(t, 3*time.Second, time.Since(start))
+		})
+	})
+
+	t.Run("limited by its own timeout", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleDying)
+			oc := newStartedOwnedComponent("test", &testutil.MockComponent{})
+			oc.quiesceTimeout = 2 * time.Second
+			hangCh := make(chan struct{})
+			defer close(hangCh)
+			oc.quiesce = func(context.Context) error {
+				<-hangCh // ignores ctx altogether
+				return nil
+			}
+			c.components = append(c.components, oc)
+
+			start := time.Now()
+			c.clDyingQuiesce(t.Context(), &shutdownBudget{})
+			test.Eq(t, 2*time.Second+c.AsyncGracePeriod, time.Since(start))
+			test.ErrorIs(t, c.Err(), lcerrors.ContextTimeoutError{Source: "Quiesce.Timeout"})
+		})
+	})
+}
@@ -2,18 +2,27 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
+	"os"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/spikesdivzero/launch-control/internal/component"
+	"github.com/spikesdivzero/launch-control/internal/debug"
 	"github.com/spikesdivzero/launch-control/internal/lcerrors"
 )
 
 type Component interface {
 	ConnectController(
+		log *slog.Logger,
 		logError func(string, error),
 		notifyOnExited func(error),
+		notifyCheckReady func(attempt int, ready bool, err error),
+		notifyShutdown func(stage string),
 		asyncGracePeriod time.Duration,
 	)
 	Start(ctx context.Context) error
@@ -21,65 +30,280 @@ type Component interface {
 	WaitReady(ctx context.Context, abortLoopCh <-chan struct{}) error
 }
 
-type ownedComponent struct {
-	name string
-	comp Component
+// A GroupMember is a single named component, as provided to [Controller.LaunchGroup].
+//
+// If DependsOn is nil, the component depends on every component launched before it.
+type GroupMember struct {
+	Name            string
+	Comp            Component
+	DependsOn       []string
+	Restart         component.RestartOptions
+	Criticality     component.Criticality
+	Reload          component.ReloadOptions
+	ShutdownReserve time.Duration
+	Quiesce         func(context.Context) error
+	QuiesceTimeout  time.Duration // zero means there's no limit
 }
 
 type Controller struct {
-	ctx context.Context
+	// The parent context is only watched for cancellation (see WatchParent). Everything the controller does runs
+	// on ctx, which keeps the parent's values, but is detached from its cancellation, so that the shutdown isn't cut
+	// short by the very thing that triggered it.
+	parentCtx          context.Context
+	ctx                context.Context
+	stopWatchingParent func() bool
 
 	Log              *slog.Logger
 	AsyncGracePeriod time.Duration
 
+	// The total time allowed for the dying stage, shared out between the components by shutdownBudget. Zero means
+	// there's no limit, other than the components' own timeouts.
+	ShutdownTimeout time.Duration
+
+	// Bounds each of the components' reload calls (see Reload). Zero means there's no limit, other than the context
+	// given to Reload.
+	ReloadTimeout time.Duration
+
+	// Called from the control loop once startup has finished. Set by the public NewController, to act on it before
+	// anything else gets to launch.
+	OnStartupFinished func()
+
+	// See ListenForSignals.
+	Signals           []os.Signal
+	EscalationSignals []os.Signal
+	ReloadSignals     []os.Signal
+	stackDumpOut      io.Writer
+	exit              func(code int)
+
+	// The command run by the public Controller.Upgrade. If UpgradePath is empty, the current executable is rerun.
+	UpgradePath string
+	UpgradeArgs []string
+
+	// The unix socket served by the public WithControlSocket, if any.
+	ControlSocketPath string
+
+	// Wraps the ShutdownReason into the cause seen by the components' contexts during the shutdown (see
+	// component.WithShutdownCause). Set by the public NewController, so that components see its own type. If nil, no
+	// cause is attached.
+	NewShutdownCause func(ShutdownReason) error
+
 	// Control Loop related bits.
-	stateMu         sync.Mutex
-	lifecycleState  lifecycleState
-	doneCh          chan struct{}
-	requestStopCh   chan struct{}
-	requestLaunchCh chan launchRequest
-	allErrors       []error
-	components      []ownedComponent
+	stateMu          sync.Mutex
+	lifecycleState   lifecycleState
+	doneCh           chan struct{}
+	requestStopCh    chan struct{}
+	requestLaunchCh  chan launchRequest
+	requestRestartCh chan *ownedComponent
+	allErrors        []error
+	containedErrs    map[int]bool // indexes into allErrors of errors from Optional components
+	shutdownReason   ShutdownReason
+	aliveAt          time.Time
+	startupFinished  bool
+
+	requestStopComponentCh chan stopComponentRequest
+	componentStops         sync.WaitGroup // shutdowns started by StopComponent, waited for by controlLoop_Dying
+	requestReplaceCh       chan replaceRequest
+	requestReloadCh        chan reloadRequest
+	requestFinishStartupCh chan chan error
+	watchdogPingCh         chan struct{}
+	controlLoopBusy        atomic.Bool // handling a request, rather than waiting for one
+
+	// The component DAG. components is in launch order, which is always a valid topological order.
+	components       []*ownedComponent
+	componentsByName map[string]*ownedComponent
+	launchCount      int
+
+	// Event delivery, see AddObserver.
+	eventsMu  sync.Mutex
+	eventSeq  uint64
+	observers []*observer
 }
 
 func New(ctx context.Context) *Controller {
 	return &Controller{
-		ctx: ctx,
+		parentCtx: ctx,
+		ctx:       context.WithoutCancel(ctx),
 
 		Log:              slog.New(slog.DiscardHandler),
 		AsyncGracePeriod: 100 * time.Millisecond,
 
-		lifecycleState:  lifecycleNew,
-		doneCh:          make(chan struct{}),
-		requestStopCh:   make(chan struct{}),
-		requestLaunchCh: make(chan launchRequest, 10), // reduce risk of deadlock
+		ReloadTimeout: 30 * time.Second,
+
+		stackDumpOut: os.Stderr,
+		exit:         os.Exit,
+
+		lifecycleState:   lifecycleNew,
+		doneCh:           make(chan struct{}),
+		requestStopCh:    make(chan struct{}),
+		requestLaunchCh:  make(chan launchRequest, 10), // reduce risk of deadlock
+		requestRestartCh: make(chan *ownedComponent),
+
+		requestStopComponentCh: make(chan stopComponentRequest),
+		requestReplaceCh:       make(chan replaceRequest),
+		requestReloadCh:        make(chan reloadRequest),
+		requestFinishStartupCh: make(chan chan error),
+		watchdogPingCh:         make(chan struct{}),
+
+		componentsByName: map[string]*ownedComponent{},
 	}
 }
 
-func (c *Controller) Launch(name string, comp Component) {
-	comp.ConnectController(
+// Launches all members concurrently, blocking until every member has either become ready or failed.
+//
+// Members are started as soon as their dependencies are ready. An error is returned if the members are not valid
+// within the component graph (duplicate names, unknown dependencies, or cycles), in which case nothing is launched.
+//
+// The returned handles are in the same order as the members.
+func (c *Controller) LaunchGroup(members []GroupMember) ([]*ComponentHandle, error) {
+	group, doneCh, err := c.sendLaunchRequest(members)
+	if err != nil {
+		return nil, err
+	}
+	<-doneCh
+
+	byName := map[string]*ownedComponent{}
+	for _, oc := range group {
+		byName[oc.name] = oc
+	}
+	handles := make([]*ComponentHandle, 0, len(members))
+	for _, m := range members {
+		handles = append(handles, &ComponentHandle{c, byName[m.Name]})
+	}
+	return handles, nil
+}
+
+func (c *Controller) connectComponent(oc *ownedComponent) {
+	oc.comp.ConnectController(
+		c.componentLog(oc),
 		func(stage string, err error) {
-			c.recordComponentError(name, stage, err)
+			c.recordComponentError(oc, stage, err)
 		},
 		func(err error) {
-			c.recordComponentError(name, "run exited", err)
-			c.RequestStop(nil)
+			c.onComponentExited(oc, err)
+		},
+		func(attempt int, ready bool, err error) {
+			c.onCheckReadyAttempt(oc, attempt, ready, err)
+		},
+		func(stage string) {
+			c.onShutdownStage(oc, stage)
 		},
 		c.AsyncGracePeriod)
-
-	<-c.sendLaunchRequest(name, comp)
 }
 
-func (c *Controller) recordComponentError(name, stage string, err error) {
+func (c *Controller) recordComponentError(oc *ownedComponent, stage string, err error) {
 	if err == nil {
 		return
 	}
-	err = lcerrors.ComponentError{Name: name, Stage: stage, Err: err}
+	err = lcerrors.ComponentError{Name: oc.name, Stage: stage, Err: err}
 
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
 
+	if oc.criticality == component.Optional {
+		if c.containedErrs == nil {
+			c.containedErrs = map[int]bool{}
+		}
+		c.containedErrs[len(c.allErrors)] = true
+	}
 	c.allErrors = append(c.allErrors, err)
+	oc.lastErr = err
+	c.emit(Event{Kind: EventErrorRecorded, Component: oc.name, Stage: stage, Err: err})
+
+	if timeoutErr := (lcerrors.ContextTimeoutError{}); errors.As(err, &timeoutErr) {
+		c.componentLog(oc).Error("component timed out", "stage", stage, "timeout", timeoutErr.Source, "error", err)
+	} else {
+		c.componentLog(oc).Error("component error", "stage", stage, "error", err)
+	}
+}
+
+func (c *Controller) componentLog(oc *ownedComponent) *slog.Logger {
+	return c.Log.With("component", oc.name)
+}
+
+func (c *Controller) onCheckReadyAttempt(oc *ownedComponent, attempt int, ready bool, err error) {
+	c.stateMu.Lock()
+	oc.checkReadyAttempts++
+	c.stateMu.Unlock()
+
+	c.emit(Event{Kind: EventCheckReadyAttempt, Component: oc.name, Attempt: attempt, Ready: ready, Err: err})
+}
+
+func (c *Controller) onShutdownStage(oc *ownedComponent, stage string) {
+	c.emit(Event{Kind: EventShutdownStage, Component: oc.name, Stage: stage})
+}
+
+// Handles a component failing to start, become ready, or keep running.
+//
+// Critical components take the whole application down with them. Optional components have their error recorded,
+// are shut down if they may still be running, and are then marked as failed so that the dying stage skips them.
+func (c *Controller) failComponent(oc *ownedComponent, stage string, err error, mayBeRunning bool) {
+	c.recordComponentError(oc, stage, err)
+
+	c.stateMu.Lock()
+	alreadyFinal := oc.state.isFinal() // e.g. a component exiting after we asked it to stop
+	oc.setState(ComponentFailed, err)
+	c.stateMu.Unlock()
+	if !alreadyFinal {
+		c.emit(Event{Kind: EventComponentFailed, Component: oc.name, Stage: stage, Err: err})
+		c.componentLog(oc).Error("component failed", "stage", stage, "critical", oc.criticality != component.Optional,
+			"error", err)
+	}
+
+	if oc.criticality != component.Optional {
+		reason := ShutdownReason{Trigger: ShutdownComponentFailed, Component: oc.name,
+			Err: lcerrors.ComponentError{Name: oc.name, Stage: stage, Err: orRunExited(err)}}
+		if stage == "run exited" {
+			reason.Trigger = ShutdownComponentExited
+		}
+		if errors.As(err, &lcerrors.ContextTimeoutError{}) {
+			reason.Trigger = ShutdownTimeout
+		}
+		c.requestStop(reason)
+		return
+	}
+
+	// The component is cleaned up individually, so its exit is expected, and the dying stage skips it.
+	c.stateMu.Lock()
+	oc.failed = true
+	oc.stopped = true
+	c.stateMu.Unlock()
+
+	if mayBeRunning {
+		if err := c.shutdownComponent(c.ctx, oc); err != nil {
+			c.recordComponentError(oc, "shutdown", err)
+		}
+	}
+}
+
+// Runs the component's Shutdown, tracking the component's state along the way. A component that isn't running
+// (e.g. it's waiting to be restarted) is simply marked as stopped.
+func (c *Controller) shutdownComponent(ctx context.Context, oc *ownedComponent) error {
+	c.stateMu.Lock()
+	running := oc.running
+	if running {
+		oc.setState(ComponentStopping, nil)
+	} else {
+		oc.setState(ComponentStopped, nil)
+	}
+	c.stateMu.Unlock()
+
+	if !running {
+		return nil
+	}
+
+	log := c.componentLog(oc)
+	log.Info("stopping component")
+	start := time.Now()
+
+	err := oc.comp.Shutdown(ctx)
+	if errors.Is(err, lcerrors.ErrShutdownAbandonedNonResponsive) {
+		c.stateMu.Lock()
+		oc.setState(ComponentAbandoned, err)
+		c.stateMu.Unlock()
+	} else {
+		log.Info("component stopped", "duration", time.Since(start))
+	}
+	return err
 }
 
 // Split out so that the lock boundary is clearly defined.
@@ -87,35 +311,60 @@ func (c *Controller) recordComponentError(name, stage string, err error) {
 // We need the lock to write, but we do not want to be holding the lock while we're waiting for the request to finish.
 //
 // Aside, we return a bidirectional channel to make testing easier, but the caller should never close the returned chan.
-func (c *Controller) sendLaunchRequest(name string, comp Component) chan struct{} {
+func (c *Controller) sendLaunchRequest(members []GroupMember) ([]*ownedComponent, chan struct{}, error) {
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
 
-	if c.lifecycleState == lifecycleNew {
-		c.lifecycleState = lifecycleAlive
-		go c.controlLoop()
-	}
-
 	doneCh := make(chan struct{})
 
-	if c.lifecycleState != lifecycleAlive {
+	if c.lifecycleState != lifecycleNew && c.lifecycleState != lifecycleAlive {
+		// Discarded, but the caller still gets handles for them.
+		group := make([]*ownedComponent, 0, len(members))
+		for _, m := range members {
+			oc := newOwnedComponent(m)
+			oc.setState(ComponentStopped, nil)
+			group = append(group, oc)
+		}
 		close(doneCh)
-		return doneCh
+		return group, doneCh, nil
 	}
 
-	c.requestLaunchCh <- launchRequest{name, comp, doneCh}
-	return doneCh
+	group, err := c.resolveGroup(members)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, oc := range group {
+		c.connectComponent(oc)
+		c.emit(Event{Kind: EventLaunchRequested, Component: oc.name})
+		c.componentLog(oc).Debug("launch requested")
+	}
+
+	if c.lifecycleState == lifecycleNew {
+		c.lifecycleState = lifecycleAlive
+		c.aliveAt = time.Now()
+		c.emitStateChange(lifecycleNew, lifecycleAlive)
+		go c.controlLoop()
+	}
+
+	c.requestLaunchCh <- launchRequest{group, doneCh}
+	return group, doneCh, nil
 }
 
 func (c *Controller) RequestStop(reason error) {
+	c.requestStop(ShutdownReason{Trigger: ShutdownRequested, Err: reason, Stack: debug.TidyStack(1)})
+}
+
+func (c *Controller) requestStop(reason ShutdownReason) {
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
 
 	// RequestStop can be called multiple times.
 
 	// We record the first error we see across all these calls, even if another stop request is already processed.
-	if reason != nil {
-		c.allErrors = append(c.allErrors, reason)
+	// Component errors have already been recorded by failComponent.
+	if reason.Err != nil && reason.Component == "" {
+		c.allErrors = append(c.allErrors, reason.Err)
+		c.emit(Event{Kind: EventErrorRecorded, Err: reason.Err})
 	}
 
 	// We shouldn't panic on a second call.
@@ -123,6 +372,7 @@ func (c *Controller) RequestStop(reason error) {
 	case <-c.requestStopCh:
 		return // already closed
 	default:
+		c.shutdownReason = reason
 		close(c.requestStopCh)
 	}
 
@@ -130,11 +380,96 @@ func (c *Controller) RequestStop(reason error) {
 	// Normal state transition (Alive->Dying) is handled by the control loop.
 	if c.lifecycleState == lifecycleNew {
 		c.lifecycleState = lifecycleDead
+		c.emitStateChange(lifecycleNew, lifecycleDead)
+		c.logStopped(c.firstError())
+		c.stopWatchingParentLocked()
 		close(c.doneCh)
 		close(c.requestLaunchCh)
 	}
 }
 
+// Starts watching the parent context, treating its cancellation as a call to RequestStop, with the context's cause
+// as the reason.
+//
+// Called once the controller has been configured, as the parent may already be done.
+func (c *Controller) WatchParent() {
+	// If the parent is already done, the callback runs straight away, on its own goroutine. Holding stateMu keeps it
+	// from getting to stopWatchingParentLocked before the field is set.
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	c.stopWatchingParent = context.AfterFunc(c.parentCtx, func() {
+		cause := context.Cause(c.parentCtx)
+		c.Log.Warn("parent context done, stopping", "cause", cause)
+		reason := ShutdownReason{Trigger: ShutdownRequested, Err: cause}
+		if errors.Is(cause, context.DeadlineExceeded) {
+			reason.Trigger = ShutdownTimeout
+		}
+		c.requestStop(reason)
+	})
+}
+
+// Must be called with stateMu held, once the controller is dead.
+func (c *Controller) stopWatchingParentLocked() {
+	if c.stopWatchingParent != nil {
+		c.stopWatchingParent()
+	}
+}
+
+func (c *Controller) isStopRequested() bool {
+	select {
+	case <-c.requestStopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reports whether the controller is running, and hasn't started shutting down.
+func (c *Controller) IsAlive() bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	return c.lifecycleState == lifecycleAlive
+}
+
+// Tells the controller that the application has launched everything it needs to, ending startup (see
+// finishStartup). Returns once OnStartupFinished has been called, or straight away if startup had already finished.
+//
+// The request is handed to the control loop, so that it can't overlap with a launch, and so that OnStartupFinished
+// runs before anything else gets to launch.
+func (c *Controller) FinishStartup() error {
+	c.stateMu.Lock()
+	state := c.lifecycleState
+	c.stateMu.Unlock()
+	if state != lifecycleAlive {
+		return lcerrors.ErrControllerNotAlive
+	}
+
+	resultCh := make(chan error, 1)
+	select {
+	case c.requestFinishStartupCh <- resultCh:
+	case <-c.requestStopCh:
+		return lcerrors.ErrControllerNotAlive
+	}
+	return <-resultCh
+}
+
+// Marks the end of startup, provided the controller is still alive (and hasn't been asked to stop), and reports
+// whether this call did so. Only called by the control loop, on behalf of FinishStartup.
+func (c *Controller) finishStartup() bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	if c.lifecycleState != lifecycleAlive || c.isStopRequested() || c.startupFinished {
+		return false
+	}
+	c.startupFinished = true
+	c.emit(Event{Kind: EventStartupFinished})
+	c.Log.Info("startup finished", "duration", time.Since(c.aliveAt))
+	return true
+}
+
 func (c *Controller) Wait() error {
 	<-c.doneCh
 	return c.Err()
@@ -144,12 +479,34 @@ func (c *Controller) Err() error {
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
 
-	if len(c.allErrors) > 0 {
-		return c.allErrors[0]
+	return c.firstError()
+}
+
+// Returns the first recorded error, skipping those of Optional components, which don't affect the outcome.
+//
+// Requires stateMu to be held.
+func (c *Controller) firstError() error {
+	for i, err := range c.allErrors {
+		if !c.containedErrs[i] {
+			return err
+		}
 	}
 	return nil
 }
 
+// Logs the final outcome of the controller. Requires stateMu to be held.
+func (c *Controller) logStopped(err error) {
+	attrs := []any{}
+	if !c.aliveAt.IsZero() {
+		attrs = append(attrs, "duration", time.Since(c.aliveAt))
+	}
+	if err != nil {
+		c.Log.Error("controller stopped", append(attrs, "errors", len(c.allErrors), "error", err)...)
+	} else {
+		c.Log.Info("controller stopped", attrs...)
+	}
+}
+
 func (c *Controller) AllErrors() []error {
 	c.stateMu.Lock()
 	defer c.stateMu.Unlock()
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"testing"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/shoenig/test"
 	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/component"
 	"github.com/spikesdivzero/launch-control/internal/lcerrors"
 	"github.com/spikesdivzero/launch-control/internal/testutil"
 )
@@ -19,6 +21,18 @@ func newTestingController(t *testing.T, initialState lifecycleState) *Controller
 	return c
 }
 
+func newTestingOwnedComponent(name string, comp Component) *ownedComponent {
+	return newOwnedComponent(GroupMember{Name: name, Comp: comp})
+}
+
+func newStartedOwnedComponent(name string, comp Component) *ownedComponent {
+	oc := newTestingOwnedComponent(name, comp)
+	oc.started = true
+	oc.running = true
+	oc.state = ComponentReady
+	return oc
+}
+
 func Test_newTestingController(t *testing.T) {
 	for _, wantState := range []lifecycleState{lifecycleAlive, lifecycleDying} {
 		c := newTestingController(t, wantState)
@@ -32,7 +46,8 @@ func TestNew(t *testing.T) {
 
 	// We saved the args
 	test.Eq(t, log, c.Log)
-	test.Eq(t, t.Context(), c.ctx)
+	test.Eq(t, t.Context(), c.parentCtx)
+	test.Nil(t, c.ctx.Done()) // detached from the parent's cancellation
 
 	// We want to always have a buffer on requestLaunchCh -- both for our tests to use, and
 	// to give ourselves a bigger safety margin to avoid a nasty deadlock.
@@ -49,6 +64,42 @@ func TestNew(t *testing.T) {
 
 	test.NotNil(t, c.requestLaunchCh)
 	testutil.ChanReadIsBlocked(t, c.requestLaunchCh)
+
+	test.NotNil(t, c.requestRestartCh)
+	testutil.ChanReadIsBlocked(t, c.requestRestartCh)
+}
+
+func TestController_WatchParent(t *testing.T) {
+	type ctxKey struct{}
+	stopErr := errors.New("parent stopped")
+
+	t.Run("cancelled", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			parent, cancel := context.WithCancelCause(context.WithValue(t.Context(), ctxKey{}, "v"))
+			c := New(parent)
+			c.WatchParent()
+			test.Eq(t, "v", c.ctx.Value(ctxKey{}))
+
+			cancel(stopErr)
+			synctest.Wait()
+			testutil.ChanReadIsClosed(t, c.doneCh)
+			test.ErrorIs(t, c.Err(), stopErr)
+			test.NoError(t, c.ctx.Err())
+		})
+	})
+
+	t.Run("stops watching once dead", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			parent, cancel := context.WithCancelCause(t.Context())
+			c := New(parent)
+			c.WatchParent()
+			c.RequestStop(nil)
+
+			cancel(stopErr)
+			synctest.Wait()
+			test.NoError(t, c.Err())
+		})
+	})
 }
 
 func TestController_Launch(t *testing.T) {
@@ -61,7 +112,7 @@ func TestController_Launch(t *testing.T) {
 		launchDone := make(chan struct{})
 		go func() {
 			defer close(launchDone)
-			c.Launch("test", mc)
+			c.LaunchGroup([]GroupMember{{Name: "test", Comp: mc}})
 		}()
 
 		synctest.Wait()
@@ -84,6 +135,44 @@ func TestController_Launch(t *testing.T) {
 	})
 }
 
+func TestController_LaunchGroup(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		mcA, mcB := &testutil.MockComponent{}, &testutil.MockComponent{}
+
+		var handles []*ComponentHandle
+		launchDone := make(chan struct{})
+		go func() {
+			defer close(launchDone)
+			// Dependencies first, so that the request order differs from the member order.
+			handles, _ = c.LaunchGroup([]GroupMember{
+				{Name: "a", Comp: mcA, DependsOn: []string{"b"}},
+				{Name: "b", Comp: mcB},
+			})
+		}()
+
+		synctest.Wait()
+		testutil.ChanReadIsBlocked(t, launchDone)
+
+		// Every member was connected, and they all arrive in a single request.
+		test.True(t, mcA.Recorder.Connect.Called)
+		test.True(t, mcB.Recorder.Connect.Called)
+
+		req, status := testutil.MaybeReadChan(c.requestLaunchCh)
+		test.Eq(t, testutil.ChanReadStatusOk, status)
+		must.Len(t, 2, req.members)
+		test.Eq(t, "b", req.members[0].name)
+		test.Eq(t, "a", req.members[1].name)
+		close(req.doneCh)
+
+		synctest.Wait()
+		testutil.ChanReadIsClosed(t, launchDone)
+		must.Len(t, 2, handles)
+		test.Eq(t, c.componentsByName["a"], handles[0].oc)
+		test.Eq(t, c.componentsByName["b"], handles[1].oc)
+	})
+}
+
 func TestController_Launch_logError(t *testing.T) {
 	t.Run("gets nil error", func(t *testing.T) {
 		c := newTestingController(t, lifecycleAlive)
@@ -93,7 +182,7 @@ func TestController_Launch_logError(t *testing.T) {
 			req := <-c.requestLaunchCh
 			close(req.doneCh)
 		}()
-		c.Launch("test", mc)
+		c.LaunchGroup([]GroupMember{{Name: "test", Comp: mc}})
 
 		mc.Recorder.Connect.LogError("test", nil)
 		test.NoError(t, c.Err())
@@ -107,7 +196,7 @@ func TestController_Launch_logError(t *testing.T) {
 			req := <-c.requestLaunchCh
 			close(req.doneCh)
 		}()
-		c.Launch("test", mc)
+		c.LaunchGroup([]GroupMember{{Name: "test", Comp: mc}})
 
 		err := errors.New("anything")
 		mc.Recorder.Connect.NotifyOnExited(err)
@@ -124,7 +213,7 @@ func TestController_Launch_notifyOnExit(t *testing.T) {
 			req := <-c.requestLaunchCh
 			close(req.doneCh)
 		}()
-		c.Launch("test", mc)
+		c.LaunchGroup([]GroupMember{{Name: "test", Comp: mc}})
 
 		mc.Recorder.Connect.NotifyOnExited(nil)
 		testutil.ChanReadIsClosed(t, c.requestStopCh) // called RequestShutdown
@@ -139,7 +228,7 @@ func TestController_Launch_notifyOnExit(t *testing.T) {
 			req := <-c.requestLaunchCh
 			close(req.doneCh)
 		}()
-		c.Launch("test", mc)
+		c.LaunchGroup([]GroupMember{{Name: "test", Comp: mc}})
 
 		err := errors.New("anything")
 		mc.Recorder.Connect.NotifyOnExited(err)
@@ -151,26 +240,32 @@ func TestController_Launch_notifyOnExit(t *testing.T) {
 func TestController_recordComponentError(t *testing.T) {
 	t.Run("nil error", func(t *testing.T) {
 		c := newTestingController(t, lifecycleAlive)
-		c.recordComponentError("foo", "bar", nil)
+		oc := newTestingOwnedComponent("foo", &testutil.MockComponent{})
+		c.recordComponentError(oc, "bar", nil)
 
 		test.Len(t, 0, c.allErrors)
+		test.NoError(t, oc.lastErr)
 	})
 
 	t.Run("gets error", func(t *testing.T) {
 		c := newTestingController(t, lifecycleAlive)
 
+		foo := newTestingOwnedComponent("foo", &testutil.MockComponent{})
 		firstErr := errors.New("fancy")
-		c.recordComponentError("foo", "bar", firstErr)
+		c.recordComponentError(foo, "bar", firstErr)
 		must.Len(t, 1, c.allErrors)
+		test.Eq(t, c.allErrors[0], foo.lastErr)
 		test.ErrorIs(t, c.allErrors[0], lcerrors.ComponentError{
 			Name:  "foo",
 			Stage: "bar",
 			Err:   firstErr,
 		})
 
+		jazz := newTestingOwnedComponent("jazz", &testutil.MockComponent{})
 		secondErr := errors.New("fancy")
-		c.recordComponentError("jazz", "hands", secondErr)
+		c.recordComponentError(jazz, "hands", secondErr)
 		must.Len(t, 2, c.allErrors)
+		test.Eq(t, c.allErrors[1], jazz.lastErr)
 		test.ErrorIs(t, c.allErrors[1], lcerrors.ComponentError{
 			Name:  "jazz",
 			Stage: "hands",
@@ -179,6 +274,98 @@ func TestController_recordComponentError(t *testing.T) {
 	})
 }
 
+func TestController_failComponent(t *testing.T) {
+	testErr := errors.New("kaput")
+
+	t.Run("critical", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		mc := &testutil.MockComponent{}
+		oc := newStartedOwnedComponent("crit", mc)
+
+		c.failComponent(oc, "wait-ready", testErr, true)
+		testutil.ChanReadIsClosed(t, c.requestStopCh)
+		test.False(t, mc.Recorder.Shutdown.Called) // left for the dying stage
+		test.False(t, oc.failed)
+		test.Eq(t, ComponentFailed, oc.state)
+		test.ErrorIs(t, oc.err, testErr)
+		testutil.ChanReadIsBlocked(t, oc.doneCh) // still running
+		test.ErrorIs(t, c.Err(), lcerrors.ComponentError{Name: "crit", Stage: "wait-ready", Err: testErr})
+	})
+
+	t.Run("optional, may be running", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		mc := &testutil.MockComponent{}
+		mc.ShutdownOptions.Err = errors.New("shutdown went badly")
+		oc := newStartedOwnedComponent("opt", mc)
+		oc.criticality = component.Optional
+
+		c.failComponent(oc, "wait-ready", testErr, true)
+		testutil.ChanReadIsBlocked(t, c.requestStopCh)
+		test.True(t, mc.Recorder.Shutdown.Called)
+		test.True(t, oc.failed)
+		test.Eq(t, []error{
+			lcerrors.ComponentError{Name: "opt", Stage: "wait-ready", Err: testErr},
+			lcerrors.ComponentError{Name: "opt", Stage: "shutdown", Err: mc.ShutdownOptions.Err},
+		}, c.AllErrors())
+	})
+
+	t.Run("optional, not running", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		mc := &testutil.MockComponent{}
+		oc := newStartedOwnedComponent("opt", mc)
+		oc.criticality = component.Optional
+
+		oc.running = false
+		c.failComponent(oc, "run exited", testErr, false)
+		testutil.ChanReadIsBlocked(t, c.requestStopCh)
+		test.False(t, mc.Recorder.Shutdown.Called)
+		test.True(t, oc.failed)
+		test.Eq(t, ComponentFailed, oc.state)
+		testutil.ChanReadIsClosed(t, oc.doneCh)
+	})
+}
+
+func TestController_shutdownComponent(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		mc := &testutil.MockComponent{}
+		oc := newStartedOwnedComponent("test", mc)
+
+		test.NoError(t, c.shutdownComponent(t.Context(), oc))
+		test.True(t, mc.Recorder.Shutdown.Called)
+		test.Eq(t, ComponentStopping, oc.state) // until the exit is reported
+
+		c.onComponentExited(oc, nil)
+		test.Eq(t, ComponentStopped, oc.state)
+		testutil.ChanReadIsClosed(t, oc.doneCh)
+		test.SliceEmpty(t, c.AllErrors())
+	})
+
+	t.Run("abandoned", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		mc := &testutil.MockComponent{}
+		mc.ShutdownOptions.Err = lcerrors.ErrShutdownAbandonedNonResponsive
+		oc := newStartedOwnedComponent("test", mc)
+
+		test.ErrorIs(t, c.shutdownComponent(t.Context(), oc), lcerrors.ErrShutdownAbandonedNonResponsive)
+		test.Eq(t, ComponentAbandoned, oc.state)
+		test.ErrorIs(t, oc.err, lcerrors.ErrShutdownAbandonedNonResponsive)
+		testutil.ChanReadIsClosed(t, oc.doneCh)
+	})
+
+	t.Run("not running", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		mc := &testutil.MockComponent{}
+		oc := newStartedOwnedComponent("test", mc)
+		oc.running = false // e.g. waiting to be restarted
+
+		test.NoError(t, c.shutdownComponent(t.Context(), oc))
+		test.False(t, mc.Recorder.Shutdown.Called)
+		test.Eq(t, ComponentStopped, oc.state)
+		testutil.ChanReadIsClosed(t, oc.doneCh)
+	})
+}
+
 func TestController_sendLaunchRequest(t *testing.T) {
 	t.Run("from New", func(t *testing.T) {
 		synctest.Test(t, func(t *testing.T) {
@@ -187,7 +374,8 @@ func TestController_sendLaunchRequest(t *testing.T) {
 			c := newTestingController(t, lifecycleNew)
 			mc := &testutil.MockComponent{}
 
-			doneCh := c.sendLaunchRequest("test", mc)
+			_, doneCh, err := c.sendLaunchRequest([]GroupMember{{Name: "test", Comp: mc}})
+			must.NoError(t, err)
 			must.NotNil(t, doneCh)
 
 			// Control Loop processed this request.
@@ -208,13 +396,15 @@ func TestController_sendLaunchRequest(t *testing.T) {
 			mc := &testutil.MockComponent{}
 
 			// This shouldn't launch the control loop, so our first channel state tests use that assumption
-			doneCh := c.sendLaunchRequest("test", mc)
+			group, doneCh, err := c.sendLaunchRequest([]GroupMember{{Name: "test", Comp: mc}})
+			must.NoError(t, err)
+			test.Eq(t, []*ownedComponent{c.componentsByName["test"]}, group)
 			test.False(t, mc.Recorder.Start.Called)
 			testutil.ChanReadIsBlocked(t, doneCh)
-			testutil.ChanReadIsOk(t, c.requestLaunchCh, launchRequest{"test", mc, doneCh})
+			testutil.ChanReadIsOk(t, c.requestLaunchCh, launchRequest{[]*ownedComponent{c.componentsByName["test"]}, doneCh})
 
 			// Verify that the control loop wasn't launched
-			dummyReq := launchRequest{"test123", mc, make(chan struct{})}
+			dummyReq := launchRequest{[]*ownedComponent{newTestingOwnedComponent("test123", mc)}, make(chan struct{})}
 			c.requestLaunchCh <- dummyReq
 			synctest.Wait() // If the loop is running, this will let it eat the request
 			testutil.ChanReadIsOk(t, c.requestLaunchCh, dummyReq)
@@ -228,13 +418,18 @@ func TestController_sendLaunchRequest(t *testing.T) {
 				mc := &testutil.MockComponent{}
 
 				// This shouldn't launch the control loop, so our first channel state tests use that assumption
-				doneCh := c.sendLaunchRequest("test", mc)
-				testutil.ChanReadIsClosed(t, doneCh)             // should be pre-closed
+				group, doneCh, err := c.sendLaunchRequest([]GroupMember{{Name: "test", Comp: mc}})
+				must.NoError(t, err)
+				testutil.ChanReadIsClosed(t, doneCh) // should be pre-closed
+				must.Len(t, 1, group)                // still gets a handle, which reports it as stopped
+				test.Eq(t, ComponentStopped, group[0].state)
+				testutil.ChanReadIsClosed(t, group[0].doneCh)
 				testutil.ChanReadIsBlocked(t, c.requestLaunchCh) // request shouldn't have been written
 				test.False(t, mc.Recorder.Start.Called)
+				test.MapEmpty(t, c.componentsByName)
 
 				// Verify that the control loop wasn't launched
-				dummyReq := launchRequest{"test123", mc, make(chan struct{})}
+				dummyReq := launchRequest{[]*ownedComponent{newTestingOwnedComponent("test123", mc)}, make(chan struct{})}
 				c.requestLaunchCh <- dummyReq
 				synctest.Wait() // If the loop is running, this will let it eat the request
 				testutil.ChanReadIsOk(t, c.requestLaunchCh, dummyReq)
@@ -243,6 +438,60 @@ func TestController_sendLaunchRequest(t *testing.T) {
 	})
 }
 
+func TestController_sendLaunchRequest_InvalidGraph(t *testing.T) {
+	c := newTestingController(t, lifecycleAlive)
+	mc := &testutil.MockComponent{}
+
+	_, _, err := c.sendLaunchRequest([]GroupMember{{Name: "existing", Comp: mc}})
+	must.NoError(t, err)
+	<-c.requestLaunchCh
+
+	for _, tt := range []struct {
+		name    string
+		members []GroupMember
+		wantErr string
+	}{
+		{
+			"duplicate of existing",
+			[]GroupMember{{Name: "existing", Comp: mc}},
+			`component "existing": name already in use`,
+		},
+		{
+			"duplicate in group",
+			[]GroupMember{{Name: "a", Comp: mc}, {Name: "a", Comp: mc}},
+			`component "a": name used more than once in group`,
+		},
+		{
+			"unknown dependency",
+			[]GroupMember{{Name: "a", Comp: mc, DependsOn: []string{"existing", "nope"}}},
+			`component "a": depends on unknown component "nope"`,
+		},
+		{
+			"cycle",
+			[]GroupMember{
+				{Name: "a", Comp: mc, DependsOn: []string{"b"}},
+				{Name: "b", Comp: mc, DependsOn: []string{"c"}},
+				{Name: "c", Comp: mc, DependsOn: []string{"existing", "a"}},
+			},
+			"dependency cycle: a -> b -> c -> a",
+		},
+		{
+			"self cycle",
+			[]GroupMember{{Name: "a", Comp: mc, DependsOn: []string{"a"}}},
+			"dependency cycle: a -> a",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			group, doneCh, err := c.sendLaunchRequest(tt.members)
+			test.EqError(t, err, tt.wantErr)
+			test.Nil(t, group)
+			test.Nil(t, doneCh)
+			testutil.ChanReadIsBlocked(t, c.requestLaunchCh)
+			test.MapLen(t, 1, c.componentsByName) // nothing reserved
+		})
+	}
+}
+
 func TestController_RequestStop(t *testing.T) {
 	t.Run("from Alive", func(t *testing.T) {
 		c := newTestingController(t, lifecycleAlive)
@@ -328,6 +577,16 @@ func TestController_Err(t *testing.T) {
 	testErr := errors.New("testy")
 	c.allErrors = append(c.allErrors, testErr, errors.New("something else"))
 	test.ErrorIs(t, c.Err(), testErr)
+
+	t.Run("skips Optional errors", func(t *testing.T) {
+		c := newTestingController(t, lifecycleNew)
+		c.allErrors = append(c.allErrors, errors.New("optional"), testErr)
+		c.containedErrs = map[int]bool{0: true}
+		test.ErrorIs(t, c.Err(), testErr)
+
+		c.allErrors = c.allErrors[:1]
+		test.Nil(t, c.Err())
+	})
 }
 
 func TestController_AllErrors(t *testing.T) {
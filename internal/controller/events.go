@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+//go:generate go tool stringer -type EventKind -trimprefix Event
+type EventKind int
+
+const (
+	EventControllerState EventKind = iota
+	EventLaunchRequested
+	EventComponentStarted
+	EventComponentReady
+	EventComponentFailed
+	EventCheckReadyAttempt
+	EventShutdownStage
+	EventErrorRecorded
+	EventStartupFinished
+)
+
+// An Event describes a single moment in the lifecycle of the controller or one of its components. Which fields are
+// set depends on the Kind.
+type Event struct {
+	Seq       uint64
+	Kind      EventKind
+	Time      time.Time
+	Component string
+
+	// EventControllerState
+	PrevState string
+	State     string
+
+	// EventCheckReadyAttempt
+	Attempt int
+	Ready   bool
+
+	// EventComponentFailed, EventShutdownStage, and EventErrorRecorded
+	Stage string
+	Err   error
+}
+
+// Registers a function to be called with every event, in order.
+//
+// Each observer is called from its own goroutine, with events queued up in the meantime, so that a slow observer
+// never blocks the controller (or any other observer). Observers added once the controller is in use miss any
+// earlier events.
+func (c *Controller) AddObserver(fn func(Event)) {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	c.observers = append(c.observers, &observer{fn: fn})
+}
+
+func (c *Controller) emitStateChange(from, to lifecycleState) {
+	c.emit(Event{Kind: EventControllerState, PrevState: from.String(), State: to.String()})
+}
+
+func (c *Controller) emit(ev Event) {
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+
+	if len(c.observers) == 0 {
+		return
+	}
+
+	c.eventSeq++
+	ev.Seq = c.eventSeq
+	ev.Time = time.Now()
+	for _, o := range c.observers {
+		o.push(ev)
+	}
+}
+
+// An observer's queue is unbounded, and is drained by a goroutine that only exists while there's something to
+// deliver, so that nothing is left running once the controller goes quiet.
+type observer struct {
+	fn func(Event)
+
+	mu       sync.Mutex
+	queue    []Event
+	draining bool
+}
+
+func (o *observer) push(ev Event) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.queue = append(o.queue, ev)
+	if !o.draining {
+		o.draining = true
+		go o.drain()
+	}
+}
+
+func (o *observer) drain() {
+	for {
+		o.mu.Lock()
+		if len(o.queue) == 0 {
+			o.draining = false
+			o.mu.Unlock()
+			return
+		}
+		ev := o.queue[0]
+		o.queue = o.queue[1:]
+		o.mu.Unlock()
+
+		o.fn(ev)
+	}
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+func TestEventKind_String(t *testing.T) {
+	test.Eq(t, "ControllerState", EventControllerState.String())
+	test.Eq(t, "ErrorRecorded", EventErrorRecorded.String())
+	test.Eq(t, "EventKind(-1)", EventKind(-1).String())
+	test.Eq(t, "StartupFinished", EventStartupFinished.String())
+	test.Eq(t, "EventKind(9)", EventKind(9).String())
+}
+
+func TestController_emit(t *testing.T) {
+	t.Run("no observers", func(t *testing.T) {
+		c := newTestingController(t, lifecycleNew)
+		c.emit(Event{Kind: EventLaunchRequested})
+		test.Eq(t, 0, c.eventSeq) // nothing to deliver to, so nothing is counted
+	})
+
+	t.Run("in order", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleNew)
+			t0 := time.Now()
+
+			var got []Event
+			c.AddObserver(func(ev Event) { got = append(got, ev) })
+
+			for i := range 5 {
+				c.emit(Event{Kind: EventCheckReadyAttempt, Attempt: i})
+			}
+			synctest.Wait()
+
+			must.Len(t, 5, got)
+			for i, ev := range got {
+				test.Eq(t, uint64(i+1), ev.Seq)
+				test.Eq(t, i, ev.Attempt)
+				test.Eq(t, t0, ev.Time)
+			}
+		})
+	})
+
+	t.Run("slow observer doesn't block", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleNew)
+
+			releaseCh := make(chan struct{})
+			var slowGot []uint64
+			c.AddObserver(func(ev Event) {
+				<-releaseCh
+				slowGot = append(slowGot, ev.Seq)
+			})
+
+			var fastGot []uint64
+			c.AddObserver(func(ev Event) { fastGot = append(fastGot, ev.Seq) })
+
+			for range 3 {
+				c.emit(Event{Kind: EventLaunchRequested})
+			}
+			synctest.Wait()
+			test.Eq(t, []uint64{1, 2, 3}, fastGot)
+			test.Len(t, 0, slowGot)
+
+			close(releaseCh)
+			synctest.Wait()
+			test.Eq(t, []uint64{1, 2, 3}, slowGot)
+		})
+	})
+}
+
+func TestController_events(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := newTestingController(t, lifecycleNew)
+
+		var got []Event
+		c.AddObserver(func(ev Event) {
+			ev.Seq, ev.Time = 0, time.Time{} // covered above
+			got = append(got, ev)
+		})
+
+		mc := &testutil.MockComponent{}
+		mc.WaitReadyOptions.Hook = func() {
+			mc.Recorder.Connect.NotifyCheckReady(1, true, nil)
+		}
+		_, err := c.LaunchGroup([]GroupMember{{Name: "a", Comp: mc}})
+		must.NoError(t, err)
+
+		mc.Recorder.Connect.NotifyShutdown("impl")
+		c.RequestStop(errors.New("bye"))
+		test.EqError(t, c.Wait(), "bye")
+		synctest.Wait()
+
+		test.Eq(t, []Event{
+			{Kind: EventLaunchRequested, Component: "a"},
+			{Kind: EventControllerState, PrevState: "New", State: "Alive"},
+			{Kind: EventComponentStarted, Component: "a"},
+			{Kind: EventCheckReadyAttempt, Component: "a", Attempt: 1, Ready: true},
+			{Kind: EventComponentReady, Component: "a"},
+			{Kind: EventShutdownStage, Component: "a", Stage: "impl"},
+			{Kind: EventErrorRecorded, Err: errors.New("bye")},
+			{Kind: EventControllerState, PrevState: "Alive", State: "Dying"},
+			{Kind: EventControllerState, PrevState: "Dying", State: "Dead"},
+		}, got)
+	})
+}
@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/spikesdivzero/launch-control/internal/component"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
+
+type reloadRequest struct {
+	ctx      context.Context
+	resultCh chan error
+}
+
+// Reloads every ready component that supports it, in two phases.
+//
+// Prepare is called on each of them in launch order. Only if every Prepare succeeds is Commit then called on each of
+// them, again in launch order. If a Prepare fails, the components that were already prepared are aborted (in
+// reverse order), and nothing is committed.
+//
+// A failed Commit leaves the component in an unknown state, so it's treated as a component failure.
+//
+// Each call is bounded by ReloadTimeout, and given up on as soon as a stop is requested, in which case
+// ErrControllerNotAlive is returned. What's left of the reload no longer matters once everything is being shut down.
+//
+// The request is handed to the control loop, so that it can't overlap with a launch, a restart, or with the
+// controller dying.
+func (c *Controller) Reload(ctx context.Context) error {
+	c.stateMu.Lock()
+	state := c.lifecycleState
+	c.stateMu.Unlock()
+	if state != lifecycleAlive {
+		return lcerrors.ErrControllerNotAlive
+	}
+
+	req := reloadRequest{ctx, make(chan error, 1)}
+	select {
+	case c.requestReloadCh <- req:
+	case <-c.requestStopCh:
+		return lcerrors.ErrControllerNotAlive
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+	return <-req.resultCh
+}
+
+func (c *Controller) clAliveDoReload(req reloadRequest) {
+	// Same reasoning as in clAliveDoLaunch.
+	select {
+	case <-c.requestStopCh:
+		req.resultCh <- lcerrors.ErrControllerNotAlive
+		return
+	default:
+	}
+
+	req.resultCh <- c.clAliveReload(req.ctx)
+}
+
+func (c *Controller) clAliveReload(ctx context.Context) error {
+	// c.components is kept in launch order.
+	c.stateMu.Lock()
+	var targets []*ownedComponent
+	for _, oc := range c.components {
+		if oc.state == ComponentReady && oc.reload.Prepare != nil {
+			targets = append(targets, oc)
+		}
+	}
+	c.stateMu.Unlock()
+
+	c.Log.Info("reloading", "components", len(targets))
+
+	for i, oc := range targets {
+		if err := c.reloadCall(ctx, oc.reload.Prepare); err != nil {
+			if errors.Is(err, lcerrors.ErrControllerNotAlive) {
+				return err
+			}
+			err = lcerrors.ComponentError{Name: oc.name, Stage: "reload-prepare", Err: err}
+			c.componentLog(oc).Warn("reload prepare failed, aborting", "stage", "reload-prepare", "error", err)
+			if abortErr := c.abortReload(ctx, targets[:i]); abortErr != nil {
+				return errors.Join(err, abortErr)
+			}
+			return err
+		}
+	}
+
+	var errs []error
+	for _, oc := range targets {
+		if err := c.reloadCall(ctx, oc.reload.Commit); err != nil {
+			if errors.Is(err, lcerrors.ErrControllerNotAlive) {
+				return errors.Join(append(errs, err)...)
+			}
+			c.failComponent(oc, "reload-commit", err, true)
+			errs = append(errs, lcerrors.ComponentError{Name: oc.name, Stage: "reload-commit", Err: err})
+		}
+	}
+	if len(errs) == 0 {
+		c.Log.Info("reloaded", "components", len(targets))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Controller) abortReload(ctx context.Context, prepared []*ownedComponent) error {
+	var errs []error
+	for _, oc := range slices.Backward(prepared) {
+		if oc.reload.Abort == nil {
+			continue
+		}
+		if err := c.reloadCall(ctx, oc.reload.Abort); err != nil {
+			if errors.Is(err, lcerrors.ErrControllerNotAlive) {
+				return errors.Join(append(errs, err)...)
+			}
+			c.componentLog(oc).Warn("reload abort failed", "stage", "reload-abort", "error", err)
+			errs = append(errs, lcerrors.ComponentError{Name: oc.name, Stage: "reload-abort", Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Runs one of a component's reload functions off the control loop, so that a hung call can neither outlast
+// ReloadTimeout, nor keep the control loop from noticing a stop request.
+func (c *Controller) reloadCall(ctx context.Context, f func(context.Context) error) error {
+	// A failed Commit may itself have requested the stop, and there's no point in going on with the rest.
+	if c.isStopRequested() {
+		return lcerrors.ErrControllerNotAlive
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	timeout := c.ReloadTimeout
+	if timeout <= 0 {
+		timeout = component.NoTimeout
+	}
+	resultCh := component.AsyncCall(ctx, "ReloadTimeout", timeout, c.AsyncGracePeriod, f)
+
+	select {
+	case r := <-resultCh:
+		err, callErr := r.Values()
+		if callErr != nil {
+			return callErr
+		}
+		return err
+	case <-c.requestStopCh:
+		// The call is abandoned, and left to notice the cancellation in its own time.
+		cancel(lcerrors.ErrControllerNotAlive)
+		return lcerrors.ErrControllerNotAlive
+	}
+}
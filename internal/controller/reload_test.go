@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/component"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+func TestController_Reload(t *testing.T) {
+	t.Run("not alive", func(t *testing.T) {
+		for _, state := range []lifecycleState{lifecycleNew, lifecycleDying, lifecycleDead} {
+			c := newTestingController(t, state)
+			test.ErrorIs(t, c.Reload(t.Context()), lcerrors.ErrControllerNotAlive)
+		}
+	})
+
+	t.Run("stop requested while waiting", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+
+			errCh := make(chan error, 1)
+			go func() { errCh <- c.Reload(t.Context()) }()
+
+			synctest.Wait()
+			testutil.ChanReadIsBlocked(t, errCh) // no control loop to pick it up
+
+			close(c.requestStopCh)
+			synctest.Wait()
+			testutil.ChanReadIsOk(t, errCh, lcerrors.ErrControllerNotAlive)
+		})
+	})
+
+	t.Run("via control loop", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+
+			committed := false
+			oc := newStartedOwnedComponent("test", &testutil.MockComponent{})
+			oc.reload = component.ReloadOptions{
+				Prepare: func(context.Context) error { return nil },
+				Commit:  func(context.Context) error { committed = true; return nil },
+			}
+			c.components = append(c.components, oc)
+
+			go c.controlLoop_Alive()
+
+			must.NoError(t, c.Reload(t.Context()))
+			test.True(t, committed)
+
+			close(c.requestStopCh)
+		})
+	})
+}
+
+func TestController_clAliveReload(t *testing.T) {
+	// Each component records its calls to the shared list.
+	type spec struct {
+		name       string
+		state      ComponentState
+		prepareErr error
+		commitErr  error
+		noReload   bool
+		noAbort    bool
+	}
+	setup := func(t *testing.T, specs ...spec) (*Controller, *[]string) {
+		c := newTestingController(t, lifecycleAlive)
+		var calls []string
+		for _, s := range specs {
+			oc := newStartedOwnedComponent(s.name, &testutil.MockComponent{})
+			if s.state != 0 {
+				oc.state = s.state
+			}
+			if !s.noReload {
+				oc.reload.Prepare = func(context.Context) error { calls = append(calls, "prepare "+s.name); return s.prepareErr }
+				oc.reload.Commit = func(context.Context) error { calls = append(calls, "commit "+s.name); return s.commitErr }
+			}
+			if !s.noAbort {
+				oc.reload.Abort = func(context.Context) error { calls = append(calls, "abort "+s.name); return nil }
+			}
+			c.components = append(c.components, oc)
+			c.componentsByName[s.name] = oc
+		}
+		return c, &calls
+	}
+
+	t.Run("all good", func(t *testing.T) {
+		c, calls := setup(t, spec{name: "a"}, spec{name: "b", noReload: true}, spec{name: "c"})
+		must.NoError(t, c.clAliveReload(t.Context()))
+		test.Eq(t, []string{"prepare a", "prepare c", "commit a", "commit c"}, *calls)
+		test.SliceEmpty(t, c.AllErrors())
+	})
+
+	t.Run("only ready components", func(t *testing.T) {
+		c, calls := setup(t, spec{name: "a", state: ComponentStarting}, spec{name: "b"})
+		must.NoError(t, c.clAliveReload(t.Context()))
+		test.Eq(t, []string{"prepare b", "commit b"}, *calls)
+	})
+
+	t.Run("prepare fails", func(t *testing.T) {
+		testErr := errors.New("bad config")
+		c, calls := setup(t,
+			spec{name: "a"}, spec{name: "b", noAbort: true}, spec{name: "c"},
+			spec{name: "d", prepareErr: testErr}, spec{name: "e"})
+
+		err := c.clAliveReload(t.Context())
+		test.ErrorIs(t, err, testErr)
+		test.Eq[error](t, lcerrors.ComponentError{Name: "d", Stage: "reload-prepare", Err: testErr}, err)
+		test.Eq(t, []string{"prepare a", "prepare b", "prepare c", "prepare d", "abort c", "abort a"}, *calls)
+
+		// The failed prepare is the caller's problem, and isn't a failure of the component.
+		test.SliceEmpty(t, c.AllErrors())
+		testutil.ChanReadIsBlocked(t, c.requestStopCh)
+	})
+
+	t.Run("commit fails", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			testErr := errors.New("oops")
+			c, calls := setup(t, spec{name: "a", commitErr: testErr}, spec{name: "b"})
+
+			err := c.clAliveReload(t.Context())
+			var ce lcerrors.ComponentError
+			must.True(t, errors.As(err, &ce))
+			test.Eq(t, lcerrors.ComponentError{Name: "a", Stage: "reload-commit", Err: testErr}, ce)
+			test.ErrorIs(t, err, lcerrors.ErrControllerNotAlive)
+
+			// With the controller stopping, there's no point in committing the rest.
+			test.Eq(t, []string{"prepare a", "prepare b", "commit a"}, *calls)
+
+			test.Eq(t, ComponentFailed, c.componentsByName["a"].state)
+			test.ErrorIs(t, c.Err(), testErr)
+			testutil.ChanReadIsClosed(t, c.requestStopCh) // critical, so the controller is stopping
+		})
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c, calls := setup(t, spec{name: "a"}, spec{name: "b"})
+			c.ReloadTimeout = time.Minute
+			c.componentsByName["b"].reload.Prepare = func(ctx context.Context) error {
+				<-ctx.Done()
+				return context.Cause(ctx)
+			}
+
+			start := time.Now()
+			err := c.clAliveReload(t.Context())
+			test.ErrorIs(t, err, lcerrors.ContextTimeoutError{Source: "ReloadTimeout"})
+			test.Eq(t, []string{"prepare a", "abort a"}, *calls)
+			test.Eq(t, time.Minute, time.Since(start))
+		})
+	})
+
+	t.Run("stop requested while running", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c, calls := setup(t, spec{name: "a"})
+			c.ReloadTimeout = 0
+			var callCtx context.Context
+			hangCh := make(chan struct{})
+			defer close(hangCh)
+			c.componentsByName["a"].reload.Prepare = func(ctx context.Context) error {
+				callCtx = ctx
+				<-hangCh // ignoring its context
+				return nil
+			}
+
+			errCh := make(chan error, 1)
+			go func() { errCh <- c.clAliveReload(t.Context()) }()
+			synctest.Wait()
+			testutil.ChanReadIsBlocked(t, errCh)
+
+			close(c.requestStopCh)
+			synctest.Wait()
+			testutil.ChanReadIsOk(t, errCh, lcerrors.ErrControllerNotAlive)
+			test.ErrorIs(t, context.Cause(callCtx), lcerrors.ErrControllerNotAlive)
+			test.SliceEmpty(t, *calls) // and nothing is aborted, as everything is being shut down anyway
+		})
+	})
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
+
+type replaceRequest struct {
+	member   GroupMember
+	resultCh chan error
+}
+
+// Starts a new component, and once it's ready, swaps it in for the existing component of the same name, which is
+// then shut down. The new component takes over the old one's place in the component graph.
+//
+// If the new component fails to start or become ready, it's shut down, the old one is left running, and the error
+// is returned. As the application as a whole hasn't failed, the error isn't recorded.
+func (c *Controller) Replace(member GroupMember) error {
+	c.stateMu.Lock()
+	state := c.lifecycleState
+	c.stateMu.Unlock()
+	if state != lifecycleAlive {
+		return lcerrors.ErrControllerNotAlive
+	}
+
+	req := replaceRequest{member, make(chan error, 1)}
+	select {
+	case c.requestReplaceCh <- req:
+	case <-c.requestStopCh:
+		return lcerrors.ErrControllerNotAlive
+	}
+	return <-req.resultCh
+}
+
+func (c *Controller) clAliveDoReplace(req replaceRequest) {
+	req.resultCh <- c.clAliveDoReplaceInner(req.member)
+}
+
+func (c *Controller) clAliveDoReplaceInner(member GroupMember) error {
+	// Same reasoning as in clAliveDoLaunch.
+	select {
+	case <-c.requestStopCh:
+		return lcerrors.ErrControllerNotAlive
+	default:
+	}
+
+	c.stateMu.Lock()
+	old, ok := c.componentsByName[member.Name]
+	c.stateMu.Unlock()
+	if !ok {
+		return fmt.Errorf("component %q: %w", member.Name, lcerrors.ErrUnknownComponent)
+	}
+
+	oc := newOwnedComponent(member)
+	oc.dependsOn = slices.Clone(old.dependsOn)
+	oc.started = true
+	oc.state = ComponentStarting
+	oc.launchOrder = old.launchOrder // as it's taking over the old component's place
+	c.connectComponent(oc)
+
+	if stage, err := c.clAliveStartReplacement(oc); err != nil {
+		// The replacement never joined the graph, so it's on us to clean it up, and its exit is expected.
+		c.stateMu.Lock()
+		oc.stopped = true
+		oc.setState(ComponentFailed, err)
+		c.stateMu.Unlock()
+		_ = c.shutdownComponent(c.ctx, oc)
+		c.componentLog(oc).Warn("replacement component failed, keeping the original", "stage", stage, "error", err)
+		return lcerrors.ComponentError{Name: member.Name, Stage: stage, Err: err}
+	}
+	close(oc.launchDoneCh)
+
+	c.stateMu.Lock()
+	oc.setState(ComponentReady, nil)
+	old.stopped = true
+	running := old.started && !old.failed
+	c.swapComponent(old, oc)
+	c.stateMu.Unlock()
+
+	if running {
+		if err := c.shutdownComponent(c.ctx, old); err != nil {
+			c.recordComponentError(old, "shutdown", err)
+		}
+	} else {
+		c.stateMu.Lock()
+		old.setState(ComponentStopped, nil)
+		c.stateMu.Unlock()
+	}
+	return nil
+}
+
+func (c *Controller) clAliveStartReplacement(oc *ownedComponent) (string, error) {
+	c.stateMu.Lock()
+	oc.running = true
+	oc.startedAt = time.Now()
+	c.stateMu.Unlock()
+
+	log := c.componentLog(oc)
+	log.Info("starting replacement component", "stage", "startup")
+
+	if err := oc.comp.Start(c.ctx); err != nil {
+		return "startup", err
+	}
+	c.emit(Event{Kind: EventComponentStarted, Component: oc.name})
+
+	if err := oc.comp.WaitReady(c.ctx, c.requestStopCh); err != nil {
+		return "wait-ready", err
+	}
+	c.emit(Event{Kind: EventComponentReady, Component: oc.name})
+	log.Info("replacement component ready", "stage", "wait-ready", "duration", time.Since(oc.startedAt))
+	return "", nil
+}
+
+// Puts the replacement in the old component's place, both in the shutdown order and as a dependency.
+//
+// Must be called with stateMu held.
+func (c *Controller) swapComponent(old, replacement *ownedComponent) {
+	c.components[slices.Index(c.components, old)] = replacement
+	c.componentsByName[old.name] = replacement
+
+	for _, oc := range c.components {
+		if i := slices.Index(oc.dependsOn, old); i >= 0 {
+			oc.dependsOn = slices.Clone(oc.dependsOn)
+			oc.dependsOn[i] = replacement
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"testing/synctest"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+func TestController_Replace(t *testing.T) {
+	t.Run("not alive", func(t *testing.T) {
+		for _, state := range []lifecycleState{lifecycleNew, lifecycleDying, lifecycleDead} {
+			c := newTestingController(t, state)
+			err := c.Replace(GroupMember{Name: "test", Comp: &testutil.MockComponent{}})
+			test.ErrorIs(t, err, lcerrors.ErrControllerNotAlive)
+		}
+	})
+
+	t.Run("stop requested while waiting", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+
+			errCh := make(chan error, 1)
+			go func() { errCh <- c.Replace(GroupMember{Name: "test", Comp: &testutil.MockComponent{}}) }()
+
+			synctest.Wait()
+			testutil.ChanReadIsBlocked(t, errCh) // no control loop to pick it up
+
+			close(c.requestStopCh)
+			synctest.Wait()
+			testutil.ChanReadIsOk(t, errCh, lcerrors.ErrControllerNotAlive)
+		})
+	})
+
+	t.Run("via control loop", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			oldMc := &testutil.MockComponent{}
+			old := newStartedOwnedComponent("test", oldMc)
+			c.components = append(c.components, old)
+			c.componentsByName["test"] = old
+
+			go c.controlLoop_Alive()
+
+			newMc := &testutil.MockComponent{}
+			must.NoError(t, c.Replace(GroupMember{Name: "test", Comp: newMc}))
+			test.True(t, newMc.Recorder.Start.Called)
+			test.True(t, oldMc.Recorder.Shutdown.Called)
+			test.Eq(t, newMc, c.componentsByName["test"].comp.(*testutil.MockComponent))
+
+			close(c.requestStopCh)
+		})
+	})
+}
+
+func TestController_clAliveDoReplaceInner(t *testing.T) {
+	// a <- b <- c
+	setup := func(t *testing.T) (*Controller, []*ownedComponent) {
+		c := newTestingController(t, lifecycleAlive)
+		for _, name := range []string{"a", "b", "c"} {
+			oc := newStartedOwnedComponent(name, &testutil.MockComponent{})
+			oc.dependsOn = c.components
+			c.components = append(c.components, oc)
+			c.componentsByName[name] = oc
+		}
+		return c, slices.Clone(c.components)
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		c, ocs := setup(t)
+		a, b, cc := ocs[0], ocs[1], ocs[2]
+		mc := &testutil.MockComponent{}
+
+		must.NoError(t, c.clAliveDoReplaceInner(GroupMember{Name: "b", Comp: mc}))
+		test.True(t, mc.Recorder.Connect.Called)
+		test.True(t, mc.Recorder.Start.Called)
+		test.True(t, mc.Recorder.WaitReady.Called)
+		test.True(t, b.comp.(*testutil.MockComponent).Recorder.Shutdown.Called)
+		test.True(t, b.stopped)
+
+		replacement := c.componentsByName["b"]
+		test.NotEq(t, b, replacement)
+		test.Eq(t, []*ownedComponent{a, replacement, cc}, c.components)
+		test.Eq(t, []*ownedComponent{a}, replacement.dependsOn)
+		test.Eq(t, []*ownedComponent{a, replacement}, cc.dependsOn)
+		test.Eq(t, []*ownedComponent{a}, b.dependsOn) // untouched
+		testutil.ChanReadIsClosed(t, replacement.launchDoneCh)
+		testutil.ChanReadIsBlocked(t, c.requestStopCh)
+		test.SliceEmpty(t, c.AllErrors())
+	})
+
+	t.Run("old shutdown fails", func(t *testing.T) {
+		c, ocs := setup(t)
+		oldMc := ocs[2].comp.(*testutil.MockComponent)
+		oldMc.ShutdownOptions.Err = lcerrors.ErrShutdownAbandonedNonResponsive
+
+		must.NoError(t, c.clAliveDoReplaceInner(GroupMember{Name: "c", Comp: &testutil.MockComponent{}}))
+		test.Eq(t, []error{
+			lcerrors.ComponentError{Name: "c", Stage: "shutdown", Err: lcerrors.ErrShutdownAbandonedNonResponsive},
+		}, c.AllErrors())
+	})
+
+	t.Run("old component failed", func(t *testing.T) {
+		c, ocs := setup(t)
+		ocs[2].failed = true
+
+		must.NoError(t, c.clAliveDoReplaceInner(GroupMember{Name: "c", Comp: &testutil.MockComponent{}}))
+		test.False(t, ocs[2].comp.(*testutil.MockComponent).Recorder.Shutdown.Called)
+		test.False(t, c.componentsByName["c"].failed)
+	})
+
+	for _, tt := range []struct {
+		stage string
+		setup func(mc *testutil.MockComponent, err error)
+	}{
+		{"startup", func(mc *testutil.MockComponent, err error) { mc.StartOptions.Err = err }},
+		{"wait-ready", func(mc *testutil.MockComponent, err error) { mc.WaitReadyOptions.Err = err }},
+	} {
+		t.Run(tt.stage+" fails", func(t *testing.T) {
+			c, ocs := setup(t)
+			testErr := errors.New("not today")
+			mc := &testutil.MockComponent{}
+			tt.setup(mc, testErr)
+
+			err := c.clAliveDoReplaceInner(GroupMember{Name: "b", Comp: mc})
+			test.Eq[error](t, lcerrors.ComponentError{Name: "b", Stage: tt.stage, Err: testErr}, err)
+			test.True(t, mc.Recorder.Shutdown.Called)
+			test.False(t, ocs[1].comp.(*testutil.MockComponent).Recorder.Shutdown.Called)
+			test.Eq(t, ocs, c.components)
+			test.Eq(t, ocs[1], c.componentsByName["b"])
+			testutil.ChanReadIsBlocked(t, c.requestStopCh)
+			test.SliceEmpty(t, c.AllErrors())
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		c, _ := setup(t)
+		mc := &testutil.MockComponent{}
+		err := c.clAliveDoReplaceInner(GroupMember{Name: "nope", Comp: mc})
+		test.ErrorIs(t, err, lcerrors.ErrUnknownComponent)
+		test.False(t, mc.Recorder.Start.Called)
+	})
+
+	t.Run("discard when stop requested", func(t *testing.T) {
+		c, _ := setup(t)
+		c.RequestStop(nil)
+		mc := &testutil.MockComponent{}
+		err := c.clAliveDoReplaceInner(GroupMember{Name: "a", Comp: mc})
+		test.ErrorIs(t, err, lcerrors.ErrControllerNotAlive)
+		test.False(t, mc.Recorder.Start.Called)
+	})
+}
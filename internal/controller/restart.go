@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/spikesdivzero/launch-control/internal/component"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
+
+// Called (via notifyOnExited) whenever a component's ImplRun returns.
+//
+// If the component's restart policy allows it, the exit is recorded and a restart is scheduled. Otherwise, the
+// component has failed (see failComponent).
+func (c *Controller) onComponentExited(oc *ownedComponent, err error) {
+	c.stateMu.Lock()
+	oc.running = false
+	stopped := oc.stopped
+	if stopped || oc.state == ComponentStopping {
+		oc.setState(ComponentStopped, err)
+	}
+	oc.maybeCloseDone()
+	c.stateMu.Unlock()
+
+	// Components stopped via StopComponent (or otherwise cleaned up individually) are expected to exit.
+	if stopped {
+		return
+	}
+
+	switch c.checkRestart(oc, err, time.Now()) {
+	case restartAllowed:
+		c.recordComponentError(oc, "restart", orRunExited(err))
+		c.stateMu.Lock()
+		oc.setState(ComponentStarting, nil)
+		c.stateMu.Unlock()
+		go c.scheduleRestart(oc)
+		return
+
+	case restartBudgetExhausted:
+		c.recordComponentError(oc, "restart", lcerrors.ErrRestartBudgetExhausted)
+	}
+
+	c.failComponent(oc, "run exited", err, false)
+}
+
+type restartDecision int
+
+const (
+	restartNotApplicable restartDecision = iota
+	restartAllowed
+	restartBudgetExhausted
+)
+
+func (c *Controller) checkRestart(oc *ownedComponent, err error, now time.Time) restartDecision {
+	switch oc.restart.Policy {
+	case component.RestartAlways:
+	case component.RestartOnFailure:
+		if err == nil {
+			return restartNotApplicable
+		}
+	default:
+		return restartNotApplicable
+	}
+
+	// Components exiting while we're stopping is expected, and never a reason to restart.
+	if c.isStopRequested() {
+		return restartNotApplicable
+	}
+
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	// Only the restarts within the window count against the budget.
+	windowStart := now.Add(-oc.restart.Window)
+	recent := oc.restartTimes[:0]
+	for _, t := range oc.restartTimes {
+		if t.After(windowStart) {
+			recent = append(recent, t)
+		}
+	}
+	oc.restartTimes = recent
+
+	if len(oc.restartTimes) >= oc.restart.MaxRestarts {
+		return restartBudgetExhausted
+	}
+	oc.restartTimes = append(oc.restartTimes, now)
+	return restartAllowed
+}
+
+// Waits out the backoff, then hands the restart to the control loop, so that it can't overlap with a launch or
+// with the controller dying.
+func (c *Controller) scheduleRestart(oc *ownedComponent) {
+	d := oc.restart.Backoff()
+	c.componentLog(oc).Warn("restarting component", "stage", "restart", "backoff", d)
+	if d > 0 {
+		select {
+		case <-time.After(d):
+		case <-c.requestStopCh:
+			return
+		}
+	}
+
+	select {
+	case c.requestRestartCh <- oc:
+	case <-c.requestStopCh:
+	}
+}
+
+func orRunExited(err error) error {
+	if err == nil {
+		return lcerrors.ErrRunExited
+	}
+	return err
+}
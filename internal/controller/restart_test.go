@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/component"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+func newRestartingOwnedComponent(policy component.RestartPolicy, maxRestarts int, window time.Duration) *ownedComponent {
+	oc := newStartedOwnedComponent("test", &testutil.MockComponent{})
+	oc.restart = component.RestartOptions{
+		Policy:      policy,
+		MaxRestarts: maxRestarts,
+		Window:      window,
+		Backoff:     func() time.Duration { return time.Second },
+	}
+	return oc
+}
+
+func TestController_checkRestart(t *testing.T) {
+	testErr := errors.New("oops")
+	t0 := time.Now()
+
+	t.Run("policies", func(t *testing.T) {
+		for _, tt := range []struct {
+			policy component.RestartPolicy
+			err    error
+			want   restartDecision
+		}{
+			{component.RestartNever, nil, restartNotApplicable},
+			{component.RestartNever, testErr, restartNotApplicable},
+			{component.RestartOnFailure, nil, restartNotApplicable},
+			{component.RestartOnFailure, testErr, restartAllowed},
+			{component.RestartAlways, nil, restartAllowed},
+			{component.RestartAlways, testErr, restartAllowed},
+		} {
+			c := newTestingController(t, lifecycleAlive)
+			oc := newRestartingOwnedComponent(tt.policy, 5, time.Minute)
+			test.Eq(t, tt.want, c.checkRestart(oc, tt.err, t0))
+		}
+	})
+
+	t.Run("stopping", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		oc := newRestartingOwnedComponent(component.RestartAlways, 5, time.Minute)
+		c.RequestStop(nil)
+		test.Eq(t, restartNotApplicable, c.checkRestart(oc, testErr, t0))
+	})
+
+	t.Run("budget within window", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		oc := newRestartingOwnedComponent(component.RestartAlways, 2, time.Minute)
+
+		test.Eq(t, restartAllowed, c.checkRestart(oc, nil, t0))
+		test.Eq(t, restartAllowed, c.checkRestart(oc, nil, t0.Add(30*time.Second)))
+		test.Eq(t, restartBudgetExhausted, c.checkRestart(oc, nil, t0.Add(45*time.Second)))
+
+		// Once the first restart ages out of the window, there's room for another.
+		test.Eq(t, restartAllowed, c.checkRestart(oc, nil, t0.Add(61*time.Second)))
+		test.Len(t, 2, oc.restartTimes)
+	})
+}
+
+func TestController_onComponentExited(t *testing.T) {
+	testErr := errors.New("oops")
+
+	t.Run("no restart", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		oc := newRestartingOwnedComponent(component.RestartNever, 5, time.Minute)
+
+		c.onComponentExited(oc, testErr)
+		testutil.ChanReadIsClosed(t, c.requestStopCh)
+		test.Eq(t, []error{
+			lcerrors.ComponentError{Name: "test", Stage: "run exited", Err: testErr},
+		}, c.AllErrors())
+		test.Eq(t, ComponentFailed, oc.state)
+		test.ErrorIs(t, oc.err, testErr)
+		testutil.ChanReadIsClosed(t, oc.doneCh)
+	})
+
+	t.Run("while stopping", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		oc := newRestartingOwnedComponent(component.RestartNever, 5, time.Minute)
+		oc.state = ComponentStopping
+		c.RequestStop(nil)
+
+		// The error is still recorded, but the component stopped as it was asked to.
+		c.onComponentExited(oc, testErr)
+		test.Eq(t, []error{
+			lcerrors.ComponentError{Name: "test", Stage: "run exited", Err: testErr},
+		}, c.AllErrors())
+		test.Eq(t, ComponentStopped, oc.state)
+		test.ErrorIs(t, oc.err, testErr)
+		testutil.ChanReadIsClosed(t, oc.doneCh)
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			oc := newRestartingOwnedComponent(component.RestartAlways, 5, time.Minute)
+
+			c.onComponentExited(oc, nil)
+			testutil.ChanReadIsBlocked(t, c.requestStopCh)
+			test.Eq(t, []error{
+				lcerrors.ComponentError{Name: "test", Stage: "restart", Err: lcerrors.ErrRunExited},
+			}, c.AllErrors())
+			test.Eq(t, ComponentStarting, oc.state)
+			test.False(t, oc.running)
+			testutil.ChanReadIsBlocked(t, oc.doneCh)
+
+			// The restart is handed off to the control loop after the backoff.
+			t0 := time.Now()
+			got := <-c.requestRestartCh
+			test.Eq(t, oc, got)
+			test.Eq(t, time.Second, time.Since(t0))
+		})
+	})
+
+	t.Run("optional", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		oc := newRestartingOwnedComponent(component.RestartNever, 5, time.Minute)
+		oc.criticality = component.Optional
+
+		c.onComponentExited(oc, testErr)
+		testutil.ChanReadIsBlocked(t, c.requestStopCh)
+		test.True(t, oc.failed)
+		test.Eq(t, []error{
+			lcerrors.ComponentError{Name: "test", Stage: "run exited", Err: testErr},
+		}, c.AllErrors())
+	})
+
+	t.Run("budget exhausted", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		oc := newRestartingOwnedComponent(component.RestartOnFailure, 0, time.Minute)
+
+		c.onComponentExited(oc, testErr)
+		testutil.ChanReadIsClosed(t, c.requestStopCh)
+		test.Eq(t, []error{
+			lcerrors.ComponentError{Name: "test", Stage: "restart", Err: lcerrors.ErrRestartBudgetExhausted},
+			lcerrors.ComponentError{Name: "test", Stage: "run exited", Err: testErr},
+		}, c.AllErrors())
+	})
+}
+
+func TestController_scheduleRestart(t *testing.T) {
+	t.Run("stop during backoff", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			oc := newRestartingOwnedComponent(component.RestartAlways, 5, time.Minute)
+
+			doneCh := make(chan struct{})
+			go func() {
+				defer close(doneCh)
+				c.scheduleRestart(oc)
+			}()
+
+			time.Sleep(time.Second / 2)
+			c.RequestStop(nil)
+			synctest.Wait()
+			testutil.ChanReadIsClosed(t, doneCh)
+			testutil.ChanReadIsBlocked(t, c.requestRestartCh)
+		})
+	})
+
+	t.Run("stop while waiting on the control loop", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			oc := newRestartingOwnedComponent(component.RestartAlways, 5, time.Minute)
+			oc.restart.Backoff = func() time.Duration { return 0 }
+
+			doneCh := make(chan struct{})
+			go func() {
+				defer close(doneCh)
+				c.scheduleRestart(oc)
+			}()
+
+			synctest.Wait()
+			testutil.ChanReadIsBlocked(t, doneCh)
+			c.RequestStop(nil)
+			synctest.Wait()
+			testutil.ChanReadIsClosed(t, doneCh)
+		})
+	})
+}
+
+func TestController_clAliveDoRestart(t *testing.T) {
+	t.Run("happy", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		oc := newRestartingOwnedComponent(component.RestartAlways, 5, time.Minute)
+		mc := oc.comp.(*testutil.MockComponent)
+
+		c.clAliveDoRestart(oc)
+		test.True(t, mc.Recorder.Start.Called)
+		test.True(t, mc.Recorder.WaitReady.Called)
+		testutil.ChanReadIsBlocked(t, c.requestStopCh)
+	})
+
+	t.Run("discard when stop requested", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		oc := newRestartingOwnedComponent(component.RestartAlways, 5, time.Minute)
+		mc := oc.comp.(*testutil.MockComponent)
+
+		c.RequestStop(nil)
+		c.clAliveDoRestart(oc)
+		test.False(t, mc.Recorder.Start.Called)
+	})
+
+	t.Run("wait-ready fails", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		oc := newRestartingOwnedComponent(component.RestartAlways, 5, time.Minute)
+		mc := oc.comp.(*testutil.MockComponent)
+		mc.WaitReadyOptions.Err = errors.New("still broken")
+
+		c.clAliveDoRestart(oc)
+		testutil.ChanReadIsClosed(t, c.requestStopCh)
+		must.Len(t, 1, c.AllErrors())
+		test.ErrorIs(t, c.Err(), mc.WaitReadyOptions.Err)
+	})
+}
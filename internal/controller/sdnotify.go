@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/spikesdivzero/launch-control/internal/sdnotify"
+)
+
+// Reports the controller's lifecycle to systemd via n.
+//
+// READY=1 is sent once startup has finished (see FinishStartup), and STOPPING=1 once the controller starts dying,
+// with STATUS= lines naming the component that's currently starting or stopping along the way. If watchdogInterval
+// is non-zero, WATCHDOG=1 is sent at that interval for as long as the control loop is alive (see watchdogLoop), up
+// until the controller starts dying.
+func (c *Controller) NotifySystemd(n *sdnotify.Notifier, watchdogInterval time.Duration) {
+	o := &systemdObserver{c: c, n: n}
+	c.AddObserver(o.onEvent)
+
+	if watchdogInterval > 0 {
+		go c.watchdogLoop(n, watchdogInterval)
+	}
+}
+
+// Only ever called from the observer's goroutine, so it needs no locking of its own.
+type systemdObserver struct {
+	c *Controller
+	n *sdnotify.Notifier
+
+	readySent bool
+}
+
+func (o *systemdObserver) onEvent(ev Event) {
+	switch ev.Kind {
+	case EventComponentStarted:
+		o.send(sdnotify.Status("Starting " + ev.Component))
+
+	case EventStartupFinished:
+		o.readySent = true
+		o.send(sdnotify.Ready, sdnotify.Status("Running"))
+
+	case EventComponentReady:
+		// Back to running after a restart or a late launch, but during startup, the next component is on its way.
+		if o.readySent {
+			o.send(sdnotify.Status("Running"))
+		}
+
+	case EventControllerState:
+		if ev.State == lifecycleDying.String() {
+			o.send(sdnotify.Stopping, sdnotify.Status("Stopping"))
+		}
+
+	case EventShutdownStage:
+		switch ev.Stage {
+		case "impl":
+			o.send(sdnotify.Status("Stopping " + ev.Component))
+		case "context":
+			o.send(sdnotify.Status("Stopping " + ev.Component + " (cancelling its context)"))
+		case "abandon":
+			o.send(sdnotify.Status("Abandoned " + ev.Component))
+		}
+	}
+}
+
+func (o *systemdObserver) send(assignments ...string) {
+	if err := o.n.Send(assignments...); err != nil {
+		o.c.Log.Warn("failed to notify systemd", "error", err)
+	}
+}
+
+// Pings the watchdog from its own goroutine, so that a ping is never held up by the control loop being busy, but
+// only while the control loop is alive, so that a stalled control loop lets the watchdog fire. Once the controller
+// starts dying, TimeoutStopSec takes over.
+func (c *Controller) watchdogLoop(n *sdnotify.Notifier, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.requestStopCh:
+			return
+		}
+
+		if !c.controlLoopAlive(interval) {
+			if !c.isStopRequested() {
+				c.Log.Warn("control loop is unresponsive, skipping watchdog ping")
+			}
+			continue
+		}
+		if err := n.Send(sdnotify.Watchdog); err != nil {
+			c.Log.Warn("failed to notify systemd", "error", err)
+		}
+	}
+}
+
+// Reports whether the control loop is either busy handling a request, which is bounded by the timeouts of whatever
+// it's doing, or is idle and picks up a ping within the timeout.
+func (c *Controller) controlLoopAlive(timeout time.Duration) bool {
+	if c.controlLoopBusy.Load() {
+		return true
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case c.watchdogPingCh <- struct{}{}:
+		return true
+	case <-timer.C:
+		return c.controlLoopBusy.Load() // it may have picked up a request in the meantime
+	case <-c.requestStopCh:
+		return false
+	}
+}
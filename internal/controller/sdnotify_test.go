@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/sdnotify"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+// Returns a notifier, along with a function that reads the next datagram sent to it (or "" on timeout).
+func newTestingNotifier(t *testing.T) (*sdnotify.Notifier, func(timeout time.Duration) string) {
+	// Socket paths are limited to ~100 bytes, which t.TempDir can exceed.
+	dir, err := os.MkdirTemp("", "sdnotify")
+	must.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	must.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	t.Setenv("NOTIFY_SOCKET", path)
+	n := sdnotify.FromEnv()
+
+	read := func(timeout time.Duration) string {
+		buf := make([]byte, 256)
+		must.NoError(t, conn.SetReadDeadline(time.Now().Add(timeout)))
+		size, err := conn.Read(buf)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return ""
+		}
+		must.NoError(t, err)
+		return string(buf[:size])
+	}
+	return n, read
+}
+
+func TestController_NotifySystemd(t *testing.T) {
+	n, read := newTestingNotifier(t)
+
+	c := newTestingController(t, lifecycleNew)
+	c.NotifySystemd(n, 0)
+
+	_, err := c.LaunchGroup([]GroupMember{
+		{Name: "a", Comp: &testutil.MockComponent{}},
+		{Name: "b", Comp: &testutil.MockComponent{}},
+	})
+	must.NoError(t, err)
+
+	// Members of a group are started in parallel.
+	starting := []string{read(5 * time.Second), read(5 * time.Second)}
+	test.SliceContainsAll(t, []string{"STATUS=Starting a", "STATUS=Starting b"}, starting)
+	test.Eq(t, "", read(50*time.Millisecond))
+
+	must.NoError(t, c.FinishStartup())
+	test.Eq(t, "READY=1\nSTATUS=Running", read(5*time.Second))
+
+	c.RequestStop(nil)
+	must.NoError(t, c.Wait())
+	test.Eq(t, "STOPPING=1\nSTATUS=Stopping", read(5*time.Second))
+}
+
+func TestSystemdObserver(t *testing.T) {
+	n, read := newTestingNotifier(t)
+	o := &systemdObserver{c: newTestingController(t, lifecycleAlive), n: n}
+
+	// Ready is only sent once startup has finished, however the components got on, including a failed (Optional)
+	// component being the last one launched.
+	o.onEvent(Event{Kind: EventComponentStarted, Component: "a"})
+	test.Eq(t, "STATUS=Starting a", read(5*time.Second))
+	o.onEvent(Event{Kind: EventComponentReady, Component: "a"})
+	o.onEvent(Event{Kind: EventComponentFailed, Component: "b"})
+	test.Eq(t, "", read(50*time.Millisecond))
+
+	o.onEvent(Event{Kind: EventStartupFinished})
+	test.Eq(t, "READY=1\nSTATUS=Running", read(5*time.Second))
+
+	// After which, a component becoming ready again only updates the status.
+	o.onEvent(Event{Kind: EventComponentStarted, Component: "d"})
+	test.Eq(t, "STATUS=Starting d", read(5*time.Second))
+	o.onEvent(Event{Kind: EventComponentReady, Component: "d"})
+	test.Eq(t, "STATUS=Running", read(5*time.Second))
+
+	o.onEvent(Event{Kind: EventShutdownStage, Component: "d", Stage: "impl"})
+	test.Eq(t, "STATUS=Stopping d", read(5*time.Second))
+	o.onEvent(Event{Kind: EventShutdownStage, Component: "d", Stage: "context"})
+	test.Eq(t, "STATUS=Stopping d (cancelling its context)", read(5*time.Second))
+	o.onEvent(Event{Kind: EventShutdownStage, Component: "d", Stage: "abandon"})
+	test.Eq(t, "STATUS=Abandoned d", read(5*time.Second))
+}
+
+func TestController_watchdogLoop(t *testing.T) {
+	n, read := newTestingNotifier(t)
+	c := newTestingController(t, lifecycleAlive)
+
+	go c.watchdogLoop(n, 10*time.Millisecond)
+
+	// Without a control loop to respond, there are no pings.
+	test.Eq(t, "", read(100*time.Millisecond))
+
+	// But a control loop that's busy handling a request, e.g. a slow reload, is still alive.
+	c.controlLoopBusy.Store(true)
+	test.Eq(t, sdnotify.Watchdog, read(5*time.Second))
+	test.Eq(t, sdnotify.Watchdog, read(5*time.Second))
+	c.controlLoopBusy.Store(false)
+	time.Sleep(20 * time.Millisecond) // let any ping that was already in flight land
+	for read(10*time.Millisecond) != "" {
+	}
+	test.Eq(t, "", read(100*time.Millisecond))
+
+	loopDoneCh := make(chan struct{})
+	go func() {
+		defer close(loopDoneCh)
+		c.controlLoop_Alive()
+	}()
+	test.Eq(t, sdnotify.Watchdog, read(5*time.Second))
+	test.Eq(t, sdnotify.Watchdog, read(5*time.Second))
+
+	close(c.requestStopCh)
+	<-loopDoneCh
+	time.Sleep(20 * time.Millisecond) // let any ping that was already in flight land
+	for read(10*time.Millisecond) != "" {
+	}
+	test.Eq(t, "", read(100*time.Millisecond))
+}
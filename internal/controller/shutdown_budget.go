@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"sync"
+	"time"
+)
+
+// Shares out the controller's ShutdownTimeout between the components, as each of them starts shutting down.
+//
+// A component gets whatever time remains, less the reserves of the components that haven't started shutting down
+// yet. That way, a slow component can't eat into the time needed by the ones stopped after it (e.g. the log and
+// metrics flushers, which are typically launched first, and so stopped last).
+type shutdownBudget struct {
+	deadline time.Time // zero if there's no limit
+
+	mu       sync.Mutex
+	reserved time.Duration
+}
+
+func newShutdownBudget(timeout time.Duration, components []*ownedComponent) *shutdownBudget {
+	b := &shutdownBudget{}
+	if timeout <= 0 {
+		return b
+	}
+	b.deadline = time.Now().Add(timeout)
+	for _, oc := range components {
+		b.reserved += oc.shutdownReserve
+	}
+	return b
+}
+
+// Returns the time left, less all the reserves that haven't been released yet. limited is false if there's no
+// budget at all.
+func (b *shutdownBudget) remaining() (d time.Duration, limited bool) {
+	if b.deadline.IsZero() {
+		return 0, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return time.Until(b.deadline) - b.reserved, true
+}
+
+// Releases the component's reserve, and returns how long its shutdown may take, which may be zero or negative if
+// the budget has run out. limited is false if there's no budget at all.
+//
+// Must be called exactly once for each component, even if it's not going to be shut down.
+func (b *shutdownBudget) take(oc *ownedComponent) (d time.Duration, limited bool) {
+	if b.deadline.IsZero() {
+		return 0, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reserved -= oc.shutdownReserve
+	return time.Until(b.deadline) - b.reserved, true
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+)
+
+func Test_shutdownBudget(t *testing.T) {
+	t.Run("no limit", func(t *testing.T) {
+		b := newShutdownBudget(0, nil)
+		_, limited := b.take(newTestingOwnedComponent("test", nil))
+		test.False(t, limited)
+	})
+
+	t.Run("reserves", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			first := newTestingOwnedComponent("first", nil)
+			second := newTestingOwnedComponent("second", nil)
+			second.shutdownReserve = 2 * time.Second
+			third := newTestingOwnedComponent("third", nil)
+			third.shutdownReserve = 3 * time.Second
+			b := newShutdownBudget(10*time.Second, []*ownedComponent{first, second, third})
+
+			d, limited := b.take(first)
+			test.True(t, limited)
+			test.Eq(t, 5*time.Second, d)
+
+			time.Sleep(6 * time.Second) // overran its share
+			d, _ = b.take(second)
+			test.Eq(t, time.Second, d)
+
+			time.Sleep(2 * time.Second)
+			d, _ = b.take(third)
+			test.Eq(t, 2*time.Second, d)
+
+			time.Sleep(3 * time.Second)
+			d, _ = b.take(newTestingOwnedComponent("late", nil))
+			test.Eq(t, -time.Second, d)
+		})
+	})
+}
@@ -0,0 +1,34 @@
+package controller
+
+//go:generate go tool stringer -type ShutdownTrigger -trimprefix Shutdown
+type ShutdownTrigger int
+
+const (
+	ShutdownRequested ShutdownTrigger = iota
+	ShutdownSignal
+	ShutdownComponentExited
+	ShutdownComponentFailed
+	ShutdownTimeout
+)
+
+// What started the controller's shutdown. Only the first stop request is recorded.
+type ShutdownReason struct {
+	Trigger ShutdownTrigger
+
+	// The component that exited or failed, if any.
+	Component string
+
+	// The reason given to RequestStop, the signal, the component's error, or the parent context's cause.
+	Err error
+
+	// The stack of the RequestStop caller, if the stop was requested via RequestStop.
+	Stack string
+}
+
+// Returns the recorded shutdown reason, and whether a stop has been requested at all.
+func (c *Controller) ShutdownReason() (ShutdownReason, bool) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	return c.shutdownReason, c.isStopRequested()
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/component"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+func TestShutdownTrigger_String(t *testing.T) {
+	test.Eq(t, "Requested", ShutdownRequested.String())
+	test.Eq(t, "Timeout", ShutdownTimeout.String())
+	test.Eq(t, "ShutdownTrigger(5)", ShutdownTrigger(5).String())
+}
+
+func TestController_ShutdownReason(t *testing.T) {
+	t.Run("not stopping", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		reason, ok := c.ShutdownReason()
+		test.False(t, ok)
+		test.Eq(t, ShutdownReason{}, reason)
+	})
+
+	t.Run("RequestStop", func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		err := errors.New("hello")
+		c.RequestStop(err)
+		c.RequestStop(errors.New("second")) // only the first is kept
+
+		reason, ok := c.ShutdownReason()
+		test.True(t, ok)
+		test.Eq(t, ShutdownRequested, reason.Trigger)
+		test.Eq(t, err, reason.Err)
+		test.Eq(t, "", reason.Component)
+		test.StrHasPrefix(t, "github.com/spikesdivzero/launch-control/internal/controller.TestController_ShutdownReason",
+			reason.Stack)
+	})
+
+	t.Run("parent deadline", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+			defer cancel()
+			c := New(ctx)
+			c.WatchParent()
+
+			time.Sleep(time.Second)
+			synctest.Wait()
+
+			reason, ok := c.ShutdownReason()
+			test.True(t, ok)
+			test.Eq(t, ShutdownTimeout, reason.Trigger)
+			test.ErrorIs(t, reason.Err, context.DeadlineExceeded)
+			test.Eq(t, "", reason.Stack)
+		})
+	})
+}
+
+func TestController_failComponent_ShutdownReason(t *testing.T) {
+	testErr := errors.New("kaput")
+	timeoutErr := lcerrors.ContextTimeoutError{Source: "CheckReady.CallTimeout"}
+
+	tests := []struct {
+		name        string
+		stage       string
+		err         error
+		wantTrigger ShutdownTrigger
+		wantErr     error
+	}{
+		{"run exited", "run exited", nil, ShutdownComponentExited, lcerrors.ErrRunExited},
+		{"run failed", "run exited", testErr, ShutdownComponentExited, testErr},
+		{"startup", "startup", testErr, ShutdownComponentFailed, testErr},
+		{"timeout", "wait-ready", timeoutErr, ShutdownTimeout, timeoutErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			oc := newStartedOwnedComponent("crit", &testutil.MockComponent{})
+			c.failComponent(oc, tt.stage, tt.err, false)
+
+			reason, ok := c.ShutdownReason()
+			test.True(t, ok)
+			test.Eq(t, tt.wantTrigger, reason.Trigger)
+			test.Eq(t, "crit", reason.Component)
+			test.ErrorIs(t, reason.Err, tt.wantErr)
+			test.Eq(t, "", reason.Stack)
+		})
+	}
+}
+
+func TestController_controlLoop_Dying_ShutdownCause(t *testing.T) {
+	reasonErr := errors.New("hello")
+	newCause := func(r ShutdownReason) error { return r.Err }
+
+	for _, withCause := range []bool{false, true} {
+		c := newTestingController(t, lifecycleDying)
+		if withCause {
+			c.NewShutdownCause = newCause
+		}
+		close(c.requestStopCh)
+		c.shutdownReason = ShutdownReason{Err: reasonErr}
+
+		mc := &testutil.MockComponent{}
+		c.components = append(c.components, newStartedOwnedComponent("test", mc))
+		c.controlLoop_Dying()
+
+		must.True(t, mc.Recorder.Shutdown.Called)
+		if withCause {
+			test.Eq(t, reasonErr, component.ShutdownCause(mc.Recorder.Shutdown.Ctx))
+		} else {
+			test.Nil(t, component.ShutdownCause(mc.Recorder.Shutdown.Ctx))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"os"
+	"os/signal"
+	"runtime/pprof"
+	"slices"
+)
+
+// The process exit code used when a signal escalates the shutdown.
+const EscalationExitCode = 3
+
+// Starts listening for the configured Signals and ReloadSignals, if any, until the controller is dead.
+//
+// The first of the Signals requests a stop, with the reason built by newReason. After that, any of the
+// EscalationSignals (which defaults to Signals) dumps the stacks of all goroutines and exits the process
+// immediately, skipping whatever remains of the graceful shutdown. An escalation signal that isn't also one of the
+// Signals escalates even without a prior stop signal.
+//
+// Any of the ReloadSignals triggers a Reload.
+func (c *Controller) ListenForSignals(newReason func(os.Signal) error) {
+	if len(c.Signals) == 0 && len(c.ReloadSignals) == 0 {
+		return
+	}
+	escalation := c.EscalationSignals
+	if len(escalation) == 0 {
+		escalation = c.Signals
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, slices.Concat(c.Signals, escalation, c.ReloadSignals)...)
+	go func() {
+		defer signal.Stop(sigCh)
+		c.signalLoop(sigCh, escalation, newReason)
+	}()
+}
+
+func (c *Controller) signalLoop(sigCh <-chan os.Signal, escalation []os.Signal, newReason func(os.Signal) error) {
+	stopping := false
+	for {
+		select {
+		case <-c.doneCh:
+			return
+
+		case sig := <-sigCh:
+			if slices.Contains(c.ReloadSignals, sig) {
+				go c.reloadOnSignal(sig)
+				continue
+			}
+
+			isStop := slices.Contains(c.Signals, sig)
+			if isStop && !stopping {
+				stopping = true
+				c.Log.Warn("received signal, stopping", "signal", sig.String())
+				c.requestStop(ShutdownReason{Trigger: ShutdownSignal, Err: newReason(sig)})
+				continue
+			}
+
+			if slices.Contains(escalation, sig) && (stopping || !isStop) {
+				c.escalate(sig)
+				return
+			}
+		}
+	}
+}
+
+func (c *Controller) escalate(sig os.Signal) {
+	c.Log.Error("received signal while stopping, exiting immediately", "signal", sig.String(),
+		"exit_code", EscalationExitCode)
+
+	_ = pprof.Lookup("goroutine").WriteTo(c.stackDumpOut, 2) // same format as an unrecovered panic
+	c.exit(EscalationExitCode)
+}
+
+// There's no caller to give up on the reload, so it's only bounded by ReloadTimeout, and by the controller stopping.
+func (c *Controller) reloadOnSignal(sig os.Signal) {
+	c.Log.Info("received signal, reloading", "signal", sig.String())
+	if err := c.Reload(c.ctx); err != nil {
+		c.Log.Error("reload failed", "signal", sig.String(), "error", err)
+	}
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+func TestController_signalLoop(t *testing.T) {
+	newReason := func(sig os.Signal) error { return errors.New("got " + sig.String()) }
+
+	type result struct {
+		exitCode  int // -1 if not called
+		stackDump string
+		err       error
+		reason    ShutdownReason
+	}
+	run := func(t *testing.T, stop, escalation []os.Signal, sigs ...os.Signal) result {
+		r := result{exitCode: -1}
+		synctest.Test(t, func(t *testing.T) {
+			// An Alive controller (without a control loop) stays alive after the stop request, so that doneCh only
+			// closes once we say so.
+			c := newTestingController(t, lifecycleAlive)
+			c.Signals = stop
+			var buf bytes.Buffer
+			c.stackDumpOut = &buf
+			c.exit = func(code int) { r.exitCode = code }
+
+			sigCh := make(chan os.Signal)
+			loopDoneCh := make(chan struct{})
+			go func() {
+				defer close(loopDoneCh)
+				c.signalLoop(sigCh, escalation, newReason)
+			}()
+
+			for _, sig := range sigs {
+				select {
+				case sigCh <- sig:
+				case <-loopDoneCh:
+					t.Fatalf("signal loop exited early")
+				}
+			}
+			synctest.Wait()
+
+			r.stackDump = buf.String()
+			r.err = c.Err()
+			r.reason, _ = c.ShutdownReason()
+
+			// Once the controller is dead, the loop must exit.
+			close(c.doneCh)
+			<-loopDoneCh
+		})
+		return r
+	}
+
+	intTerm := []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+	t.Run("first signal stops", func(t *testing.T) {
+		r := run(t, intTerm, intTerm, syscall.SIGTERM)
+		test.EqError(t, r.err, "got terminated")
+		test.Eq(t, ShutdownSignal, r.reason.Trigger)
+		test.Eq(t, r.err, r.reason.Err)
+		test.Eq(t, -1, r.exitCode)
+		test.Eq(t, "", r.stackDump)
+	})
+
+	t.Run("second signal escalates", func(t *testing.T) {
+		r := run(t, intTerm, intTerm, syscall.SIGTERM, os.Interrupt)
+		test.EqError(t, r.err, "got terminated")
+		test.Eq(t, EscalationExitCode, r.exitCode)
+		test.StrContains(t, r.stackDump, "goroutine")
+	})
+
+	t.Run("non-escalation signal is ignored while stopping", func(t *testing.T) {
+		r := run(t, intTerm, []os.Signal{syscall.SIGQUIT}, syscall.SIGTERM, syscall.SIGTERM)
+		test.Eq(t, -1, r.exitCode)
+	})
+
+	t.Run("escalation-only signal escalates immediately", func(t *testing.T) {
+		r := run(t, intTerm, []os.Signal{syscall.SIGQUIT}, syscall.SIGQUIT)
+		test.NoError(t, r.err)
+		test.Eq(t, EscalationExitCode, r.exitCode)
+	})
+}
+
+func TestController_ListenForSignals(t *testing.T) {
+	t.Run("none configured", func(t *testing.T) {
+		c := newTestingController(t, lifecycleNew)
+		c.ListenForSignals(func(os.Signal) error { panic("not expected") })
+	})
+
+	t.Run("real signal", func(t *testing.T) {
+		c := newTestingController(t, lifecycleNew)
+		c.Signals = []os.Signal{syscall.SIGUSR1}
+		c.ListenForSignals(func(sig os.Signal) error { return errors.New("got " + sig.String()) })
+
+		must.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+		select {
+		case <-c.doneCh:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the signal to stop the controller")
+		}
+		test.EqError(t, c.Err(), "got user defined signal 1")
+	})
+}
+
+func TestController_signalLoop_reload(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := newTestingController(t, lifecycleAlive)
+		c.ReloadSignals = []os.Signal{syscall.SIGHUP}
+
+		sigCh := make(chan os.Signal)
+		go c.signalLoop(sigCh, nil, func(os.Signal) error { panic("not expected") })
+
+		sigCh <- syscall.SIGHUP
+		req := <-c.requestReloadCh // handed to the control loop
+		req.resultCh <- nil
+
+		sigCh <- syscall.SIGHUP
+		req = <-c.requestReloadCh
+		req.resultCh <- errors.New("failed reloads are only logged")
+
+		synctest.Wait()
+		testutil.ChanReadIsBlocked(t, c.requestStopCh)
+		close(c.doneCh)
+	})
+}
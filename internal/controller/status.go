@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/spikesdivzero/launch-control/internal/component"
+)
+
+// A point-in-time snapshot of the controller and its components.
+type Status struct {
+	State           string
+	StartupFinished bool
+	Components      []ComponentStatus
+}
+
+type ComponentStatus struct {
+	Name               string
+	State              ComponentState
+	Criticality        component.Criticality
+	LaunchOrder        int
+	StartedAt          time.Time
+	ReadyAt            time.Time
+	StoppedAt          time.Time
+	CheckReadyAttempts int
+	LastError          error
+}
+
+// Returns a snapshot of the controller and every component in the component graph, in launch order. Components
+// removed via StopComponent or Replace are no longer included.
+func (c *Controller) Status() Status {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	s := Status{
+		State:           c.lifecycleState.String(),
+		StartupFinished: c.startupFinished,
+		Components:      make([]ComponentStatus, 0, len(c.components)),
+	}
+	for _, oc := range c.components {
+		s.Components = append(s.Components, ComponentStatus{
+			Name:               oc.name,
+			State:              oc.state,
+			Criticality:        oc.criticality,
+			LaunchOrder:        oc.launchOrder,
+			StartedAt:          oc.startedAt,
+			ReadyAt:            oc.readyAt,
+			StoppedAt:          oc.stoppedAt,
+			CheckReadyAttempts: oc.checkReadyAttempts,
+			LastError:          oc.lastErr,
+		})
+	}
+	return s
+}
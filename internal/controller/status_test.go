@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+func TestController_Status(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		c := newTestingController(t, lifecycleNew)
+		test.Eq(t, Status{State: "New", Components: []ComponentStatus{}}, c.Status())
+	})
+
+	t.Run("startup finished", func(t *testing.T) {
+		c := newTestingController(t, lifecycleNew)
+		test.False(t, c.finishStartup())
+		test.False(t, c.Status().StartupFinished)
+
+		c.lifecycleState = lifecycleAlive
+		test.True(t, c.finishStartup())
+		test.True(t, c.Status().StartupFinished)
+		test.False(t, c.finishStartup()) // only once
+
+		// Once stopping, it's too late for startup to finish.
+		c = newTestingController(t, lifecycleAlive)
+		close(c.requestStopCh)
+		test.False(t, c.finishStartup())
+		test.False(t, c.Status().StartupFinished)
+	})
+
+	t.Run("tracks components", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			t0 := time.Now()
+
+			okMc := &testutil.MockComponent{}
+			okMc.WaitReadyOptions.Hook = func() {
+				notify := okMc.Recorder.Connect.NotifyCheckReady
+				notify(1, false, nil)
+				notify(2, true, nil)
+			}
+			okMc.WaitReadyOptions.Sleep = time.Second
+
+			badMc := &testutil.MockComponent{}
+			badMc.WaitReadyOptions.Err = errors.New("nope")
+
+			_, doneCh, err := c.sendLaunchRequest([]GroupMember{
+				{Name: "ok", Comp: okMc},
+				{Name: "bad", Comp: badMc, DependsOn: []string{}},
+			})
+			must.NoError(t, err)
+			c.clAliveDoLaunch(<-c.requestLaunchCh)
+			<-doneCh
+
+			s := c.Status()
+			test.Eq(t, "Alive", s.State)
+			must.Len(t, 2, s.Components)
+
+			ok := s.Components[0]
+			test.Eq(t, "ok", ok.Name)
+			test.Eq(t, ComponentReady, ok.State)
+			test.Eq(t, 1, ok.LaunchOrder)
+			test.Eq(t, t0, ok.StartedAt)
+			test.Eq(t, t0.Add(time.Second), ok.ReadyAt)
+			test.True(t, ok.StoppedAt.IsZero())
+			test.Eq(t, 2, ok.CheckReadyAttempts)
+			test.NoError(t, ok.LastError)
+
+			bad := s.Components[1]
+			test.Eq(t, "bad", bad.Name)
+			test.Eq(t, ComponentFailed, bad.State)
+			test.Eq(t, 2, bad.LaunchOrder)
+			test.Eq(t, t0, bad.StartedAt)
+			test.True(t, bad.ReadyAt.IsZero())
+			test.Eq(t, t0, bad.StoppedAt)
+			test.ErrorIs(t, bad.LastError, badMc.WaitReadyOptions.Err)
+		})
+	})
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
+
+type stopComponentRequest struct {
+	ctx      context.Context
+	name     string
+	resultCh chan error
+}
+
+// Removes a single component from the component graph, then shuts it down, leaving the rest of the application
+// running. Returns the outcome of the component's Shutdown, which is bounded by ShutdownTimeout, if there is one.
+//
+// The request is handed to the control loop, so that it can't overlap with a launch, a restart, or with the
+// controller starting to die. The shutdown itself runs off the control loop, and the dying stage waits for it.
+func (c *Controller) StopComponent(ctx context.Context, name string) error {
+	c.stateMu.Lock()
+	state := c.lifecycleState
+	c.stateMu.Unlock()
+	if state != lifecycleAlive {
+		return lcerrors.ErrControllerNotAlive
+	}
+
+	req := stopComponentRequest{ctx, name, make(chan error, 1)}
+	select {
+	case c.requestStopComponentCh <- req:
+	case <-c.requestStopCh:
+		return lcerrors.ErrControllerNotAlive
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+	return <-req.resultCh
+}
+
+func (c *Controller) clAliveDoStopComponent(req stopComponentRequest) {
+	// Same reasoning as in clAliveDoLaunch.
+	select {
+	case <-c.requestStopCh:
+		req.resultCh <- lcerrors.ErrControllerNotAlive
+		return
+	default:
+	}
+
+	c.stateMu.Lock()
+	oc, ok := c.componentsByName[req.name]
+	var running bool
+	if ok {
+		// From here on, the component exiting is expected, and never a reason to fail or restart. It's taken out of
+		// the graph straight away, so that nothing else (such as the dying stage) gets to stop it as well.
+		oc.stopped = true
+		running = oc.started && !oc.failed
+		if !running {
+			oc.setState(ComponentStopped, nil)
+		}
+		c.removeComponent(oc)
+	}
+	c.stateMu.Unlock()
+	if !ok {
+		req.resultCh <- fmt.Errorf("component %q: %w", req.name, lcerrors.ErrUnknownComponent)
+		return
+	}
+	if !running {
+		req.resultCh <- nil
+		return
+	}
+
+	ctx, cancel := req.ctx, context.CancelFunc(func() {})
+	if c.ShutdownTimeout > 0 {
+		ctx, cancel = context.WithTimeoutCause(ctx, c.ShutdownTimeout,
+			lcerrors.ContextTimeoutError{Source: "ControllerShutdownTimeout"})
+	}
+	c.componentStops.Go(func() {
+		defer cancel()
+		err := c.shutdownComponent(ctx, oc)
+		if err != nil {
+			c.recordComponentError(oc, "shutdown", err)
+		}
+		req.resultCh <- err
+	})
+}
+
+// Removes a component from the graph. Anything that depended on it now depends on its dependencies instead, so
+// that the shutdown order of the remaining components is preserved.
+//
+// Must be called with stateMu held.
+func (c *Controller) removeComponent(removed *ownedComponent) {
+	c.components = slices.DeleteFunc(c.components, func(oc *ownedComponent) bool { return oc == removed })
+	delete(c.componentsByName, removed.name)
+
+	for _, oc := range c.components {
+		i := slices.Index(oc.dependsOn, removed)
+		if i < 0 {
+			continue
+		}
+		deps := slices.Delete(slices.Clone(oc.dependsOn), i, i+1)
+		for _, dep := range removed.dependsOn {
+			if !slices.Contains(deps, dep) {
+				deps = append(deps, dep)
+			}
+		}
+		oc.dependsOn = deps
+	}
+}
+
+// Stands in for a dependency that was stopped after a launch request naming it was sent, but before the control
+// loop got around to processing it.
+func newStoppedPlaceholder(name string) *ownedComponent {
+	oc := &ownedComponent{
+		name:         name,
+		launchDoneCh: make(chan struct{}),
+		launchErr:    lcerrors.ErrComponentStopped,
+		stopped:      true,
+		doneCh:       make(chan struct{}),
+	}
+	close(oc.launchDoneCh)
+	oc.setState(ComponentStopped, nil)
+	return oc
+}
@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/component"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+func TestController_StopComponent(t *testing.T) {
+	t.Run("not alive", func(t *testing.T) {
+		for _, state := range []lifecycleState{lifecycleNew, lifecycleDying, lifecycleDead} {
+			c := newTestingController(t, state)
+			test.ErrorIs(t, c.StopComponent(t.Context(), "test"), lcerrors.ErrControllerNotAlive)
+		}
+	})
+
+	t.Run("stop requested while waiting", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+
+			errCh := make(chan error, 1)
+			go func() { errCh <- c.StopComponent(t.Context(), "test") }()
+
+			synctest.Wait()
+			testutil.ChanReadIsBlocked(t, errCh) // no control loop to pick it up
+
+			close(c.requestStopCh)
+			synctest.Wait()
+			testutil.ChanReadIsOk(t, errCh, lcerrors.ErrControllerNotAlive)
+		})
+	})
+
+	t.Run("ctx cancelled while waiting", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			cause := errors.New("never mind")
+			ctx, cancel := context.WithCancelCause(t.Context())
+
+			errCh := make(chan error, 1)
+			go func() { errCh <- c.StopComponent(ctx, "test") }()
+
+			cancel(cause)
+			synctest.Wait()
+			testutil.ChanReadIsOk(t, errCh, cause)
+		})
+	})
+
+	t.Run("via control loop", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			mc := &testutil.MockComponent{}
+			oc := newStartedOwnedComponent("test", mc)
+			c.components = append(c.components, oc)
+			c.componentsByName["test"] = oc
+
+			go c.controlLoop_Alive()
+
+			must.NoError(t, c.StopComponent(t.Context(), "test"))
+			test.True(t, mc.Recorder.Shutdown.Called)
+			test.SliceEmpty(t, c.components)
+			testutil.ChanReadIsBlocked(t, c.requestStopCh) // still alive
+
+			close(c.requestStopCh)
+		})
+	})
+
+	t.Run("off the control loop", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			c := newTestingController(t, lifecycleAlive)
+			c.ShutdownTimeout = time.Minute
+			mc := &testutil.MockComponent{}
+			mc.ShutdownOptions.Sleep = time.Hour // ignoring its context
+			oc := newStartedOwnedComponent("test", mc)
+			c.components = append(c.components, oc)
+			c.componentsByName["test"] = oc
+
+			clExited := make(chan struct{})
+			go func() {
+				defer close(clExited)
+				c.controlLoop_Alive()
+			}()
+
+			errCh := make(chan error, 1)
+			go func() { errCh <- c.StopComponent(t.Context(), "test") }()
+			synctest.Wait()
+			testutil.ChanReadIsBlocked(t, errCh)
+
+			// The control loop carries on while the component is being stopped.
+			must.NoError(t, c.FinishStartup())
+
+			// The shutdown is bounded by ShutdownTimeout.
+			time.Sleep(time.Minute)
+			synctest.Wait()
+			test.ErrorIs(t, context.Cause(mc.Recorder.Shutdown.Ctx),
+				lcerrors.ContextTimeoutError{Source: "ControllerShutdownTimeout"})
+
+			// The dying stage waits for it to finish.
+			close(c.requestStopCh)
+			<-clExited
+			c.lifecycleState = lifecycleDying
+			dyingDone := make(chan struct{})
+			go func() {
+				defer close(dyingDone)
+				c.controlLoop_Dying()
+			}()
+			synctest.Wait()
+			testutil.ChanReadIsBlocked(t, dyingDone)
+
+			time.Sleep(time.Hour)
+			synctest.Wait()
+			testutil.ChanReadIsClosed(t, dyingDone)
+			must.NoError(t, <-errCh)
+		})
+	})
+}
+
+func TestController_clAliveDoStopComponent(t *testing.T) {
+	doStop := func(c *Controller, name string) error {
+		req := stopComponentRequest{context.Background(), name, make(chan error, 1)}
+		c.clAliveDoStopComponent(req)
+		return <-req.resultCh
+	}
+
+	newTestingControllerWith := func(t *testing.T, names ...string) (*Controller, []*testutil.MockComponent) {
+		c := newTestingController(t, lifecycleAlive)
+		var mcs []*testutil.MockComponent
+		for _, name := range names {
+			mc := &testutil.MockComponent{}
+			oc := newStartedOwnedComponent(name, mc)
+			oc.dependsOn = c.components
+			c.components = append(c.components, oc)
+			c.componentsByName[name] = oc
+			mcs = append(mcs, mc)
+		}
+		return c, mcs
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		c, mcs := newTestingControllerWith(t, "a", "b", "c")
+		a, c2 := c.components[0], c.components[2]
+
+		must.NoError(t, doStop(c, "b"))
+		test.False(t, mcs[0].Recorder.Shutdown.Called)
+		test.True(t, mcs[1].Recorder.Shutdown.Called)
+		test.False(t, mcs[2].Recorder.Shutdown.Called)
+		test.Eq(t, []*ownedComponent{a, c2}, c.components)
+		test.MapNotContainsKey(t, c.componentsByName, "b")
+		test.Eq(t, []*ownedComponent{a}, c2.dependsOn)
+		testutil.ChanReadIsBlocked(t, c.requestStopCh)
+		test.SliceEmpty(t, c.AllErrors())
+	})
+
+	t.Run("shutdown fails", func(t *testing.T) {
+		c, mcs := newTestingControllerWith(t, "a")
+		mcs[0].ShutdownOptions.Err = lcerrors.ErrShutdownAbandonedNonResponsive
+
+		test.ErrorIs(t, doStop(c, "a"), lcerrors.ErrShutdownAbandonedNonResponsive)
+		test.SliceEmpty(t, c.components)
+		test.Eq(t, []error{
+			lcerrors.ComponentError{Name: "a", Stage: "shutdown", Err: lcerrors.ErrShutdownAbandonedNonResponsive},
+		}, c.AllErrors())
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		c, _ := newTestingControllerWith(t, "a")
+		test.ErrorIs(t, doStop(c, "nope"), lcerrors.ErrUnknownComponent)
+		test.Len(t, 1, c.components)
+	})
+
+	t.Run("failed component", func(t *testing.T) {
+		c, mcs := newTestingControllerWith(t, "a")
+		c.components[0].failed = true
+
+		must.NoError(t, doStop(c, "a"))
+		test.False(t, mcs[0].Recorder.Shutdown.Called)
+		test.SliceEmpty(t, c.components)
+	})
+
+	t.Run("discard when stop requested", func(t *testing.T) {
+		c, mcs := newTestingControllerWith(t, "a")
+		c.RequestStop(nil)
+
+		test.ErrorIs(t, doStop(c, "a"), lcerrors.ErrControllerNotAlive)
+		test.False(t, mcs[0].Recorder.Shutdown.Called)
+		test.Len(t, 1, c.components)
+	})
+
+	t.Run("exit is expected", func(t *testing.T) {
+		c, _ := newTestingControllerWith(t, "a")
+		oc := c.components[0]
+		oc.restart = component.RestartOptions{Policy: component.RestartAlways, MaxRestarts: 5}
+
+		must.NoError(t, doStop(c, "a"))
+		c.onComponentExited(oc, errors.New("interrupted"))
+		testutil.ChanReadIsBlocked(t, c.requestStopCh)
+		test.SliceEmpty(t, oc.restartTimes)
+		test.SliceEmpty(t, c.AllErrors())
+
+		// A restart that was already pending is dropped too.
+		c.clAliveDoRestart(oc)
+		test.False(t, oc.comp.(*testutil.MockComponent).Recorder.Start.Called)
+	})
+}
+
+func TestController_removeComponent(t *testing.T) {
+	c := newTestingController(t, lifecycleAlive)
+	mc := &testutil.MockComponent{}
+
+	// a <- b <- c, and d depends on everything.
+	group, err := c.resolveGroup([]GroupMember{
+		{Name: "a", Comp: mc, DependsOn: []string{}},
+		{Name: "b", Comp: mc, DependsOn: []string{"a"}},
+		{Name: "c", Comp: mc, DependsOn: []string{"b"}},
+		{Name: "d", Comp: mc, DependsOn: []string{"a", "b", "c"}},
+	})
+	must.NoError(t, err)
+	c.linkGroup(group)
+	c.components = append(c.components, group...)
+	a, b, cc, d := group[0], group[1], group[2], group[3]
+
+	c.removeComponent(b)
+	test.Eq(t, []*ownedComponent{a, cc, d}, c.components)
+	test.MapNotContainsKey(t, c.componentsByName, "b")
+	test.Eq(t, []*ownedComponent{a}, cc.dependsOn)    // inherits b's dependencies
+	test.Eq(t, []*ownedComponent{a, cc}, d.dependsOn) // without duplicates
+	test.Eq(t, []*ownedComponent{a}, b.dependsOn)     // untouched
+}
+
+func Test_newStoppedPlaceholder(t *testing.T) {
+	oc := newStoppedPlaceholder("gone")
+	test.Eq(t, "gone", oc.name)
+	testutil.ChanReadIsClosed(t, oc.launchDoneCh)
+	test.ErrorIs(t, oc.launchErr, lcerrors.ErrComponentStopped)
+}
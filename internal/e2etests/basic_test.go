@@ -68,3 +68,70 @@ func TestRunExitingWithNoErrorCausesShutdown(t *testing.T) {
 		test.ErrorIs(t, ctrl.Wait(), nil)
 	})
 }
+
+// Cancelling the parent context is the same as a RequestStop, and doesn't cut the graceful shutdown short.
+func TestParentContextCancelled(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		type ctxKey struct{}
+		parent, cancel := context.WithCancelCause(context.WithValue(t.Context(), ctxKey{}, "v"))
+		ctrl := launch.NewController(parent)
+
+		var runCtxErr, shutdownCtxErr error
+		var shutdownCtxValue any
+		ctrl.Launch("server", launch.WithRun(
+			func(ctx context.Context) error {
+				time.Sleep(time.Hour) // stands in for serving until shut down
+				runCtxErr = ctx.Err()
+				return nil
+			},
+			func(ctx context.Context) error {
+				shutdownCtxErr = ctx.Err()
+				shutdownCtxValue = ctx.Value(ctxKey{})
+				return nil
+			}),
+			launch.WithShutdownCompletionTimeout(2*time.Hour))
+
+		err := errors.New("terminated")
+		time.AfterFunc(time.Second, func() { cancel(err) })
+
+		test.ErrorIs(t, ctrl.Wait(), err)
+		test.NoError(t, runCtxErr)
+		test.NoError(t, shutdownCtxErr)
+		test.Eq(t, "v", shutdownCtxValue)
+	})
+}
+
+// The components can tell why they're being stopped, from the contexts they're given.
+func TestShutdownReason(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		var stopReason, runReason launch.ShutdownReason
+		ctrl.Launch("one", launch.WithStartStop(
+			func(ctx context.Context) error { return nil },
+			func(ctx context.Context) error {
+				stopReason, _ = launch.ShutdownReasonFromContext(ctx)
+				return nil
+			}))
+		ctrl.Launch("two", launch.WithRun(
+			func(ctx context.Context) error {
+				<-ctx.Done()
+				runReason, _ = launch.ShutdownReasonFromContext(ctx)
+				return nil
+			},
+			func(ctx context.Context) error { return nil }), // ignored, so the context gets cancelled
+			launch.WithShutdownCompletionTimeout(time.Second))
+
+		err := errors.New("hello")
+		time.AfterFunc(time.Second, func() { ctrl.RequestStop(err) })
+		test.ErrorIs(t, ctrl.Wait(), err)
+
+		reason, ok := ctrl.ShutdownReason()
+		test.True(t, ok)
+		test.Eq(t, launch.ShutdownRequested, reason.Trigger)
+		test.Eq(t, err, reason.Err)
+		test.StrContains(t, reason.Stack, "e2etests.TestShutdownReason")
+		test.Eq(t, reason, stopReason)
+		test.Eq(t, reason, runReason)
+	})
+}
@@ -0,0 +1,130 @@
+package e2etests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/spikesdivzero/launch-control"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
+
+func TestOptionalComponentExits(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		testErr := errors.New("sidecar crashed")
+		ctrl.Launch("sidecar",
+			launch.WithRun(
+				func(ctx context.Context) error {
+					time.Sleep(time.Second)
+					return testErr
+				},
+				func(ctx context.Context) error { return nil }),
+			launch.WithCriticality(launch.Optional))
+
+		var mainStopped bool
+		stopCh := make(chan struct{})
+		ctrl.Launch("main",
+			launch.WithDependsOn(),
+			launch.WithRun(
+				func(ctx context.Context) error {
+					<-stopCh
+					return nil
+				},
+				func(ctx context.Context) error {
+					mainStopped = true
+					close(stopCh)
+					return nil
+				}))
+
+		// The sidecar's exit is recorded, but doesn't stop the application.
+		time.Sleep(time.Minute)
+		synctest.Wait()
+		test.False(t, mainStopped)
+		test.Eq(t, []error{
+			lcerrors.ComponentError{Name: "sidecar", Stage: "run exited", Err: testErr},
+		}, ctrl.AllErrors())
+
+		// Nor does it become the controller's error.
+		ctrl.RequestStop(nil)
+		test.NoError(t, ctrl.Wait())
+		test.True(t, mainStopped)
+	})
+}
+
+func TestOptionalComponentNotReady(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		testErr := errors.New("cache unavailable")
+		ctrl.Launch("cache",
+			launch.WithStartStop(
+				func(ctx context.Context) error { return nil },
+				func(ctx context.Context) error { return nil }),
+			launch.WithCheckReady(func(ctx context.Context) (bool, error) { return false, testErr }),
+			launch.WithCriticality(launch.Optional))
+
+		var warmerStarted bool
+		ctrl.Launch("cache-warmer",
+			launch.WithDependsOn("cache"),
+			launch.WithStartStop(
+				func(ctx context.Context) error {
+					warmerStarted = true
+					return nil
+				},
+				func(ctx context.Context) error { return nil }),
+			launch.WithCriticality(launch.Optional))
+
+		var apiStarted bool
+		ctrl.Launch("api",
+			launch.WithDependsOn(),
+			launch.WithStartStop(
+				func(ctx context.Context) error {
+					apiStarted = true
+					return nil
+				},
+				func(ctx context.Context) error { return nil }))
+
+		synctest.Wait()
+		test.False(t, warmerStarted)
+		test.True(t, apiStarted)
+
+		ctrl.RequestStop(nil)
+		ctrl.Wait()
+		test.Eq(t, 2, len(ctrl.AllErrors()))
+		test.ErrorIs(t, ctrl.AllErrors()[0], testErr)
+		test.Eq[error](t, lcerrors.ComponentError{Name: "cache-warmer", Stage: "startup", Err: lcerrors.DependencyError{
+			Name: "cache",
+			Err:  lcerrors.ErrDependencyNotReady,
+		}}, ctrl.AllErrors()[1])
+	})
+}
+
+// The usual sequential launches, without WithDependsOn: the components launched after a failed Optional one don't
+// depend on it.
+func TestOptionalComponentFailed_launchOrder(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		ctrl.Launch("metrics",
+			launch.WithStartStop(
+				func(ctx context.Context) error { return nil },
+				func(ctx context.Context) error { return nil }),
+			launch.WithCheckReadyMaxAttempts(1),
+			launch.WithCheckReady(func(ctx context.Context) (bool, error) { return false, nil }),
+			launch.WithCriticality(launch.Optional))
+
+		api := ctrl.Launch("api", withDummyStartStop())
+		test.Eq(t, launch.ComponentReady, api.State())
+		test.Eq(t, "Alive", ctrl.Status().State)
+
+		ctrl.RequestStop(nil)
+		test.NoError(t, ctrl.Wait())
+		test.Len(t, 1, ctrl.AllErrors())
+		test.Eq(t, launch.ComponentStopped, api.State())
+	})
+}
@@ -0,0 +1,85 @@
+package e2etests
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/spikesdivzero/launch-control"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+func TestDependsOnOrdering(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		var mu sync.Mutex
+		events := []string{}
+		record := func(s string) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, s)
+		}
+
+		member := func(name string, startD time.Duration, deps ...string) launch.GroupMember {
+			return launch.Member(name,
+				launch.WithStartStop(
+					func(context.Context) error {
+						record("start " + name)
+						time.Sleep(startD)
+						return nil
+					},
+					func(context.Context) error {
+						record("stop " + name)
+						return nil
+					}),
+				launch.WithCheckReady(func(context.Context) (bool, error) { return true, nil }),
+				launch.WithDependsOn(deps...))
+		}
+
+		// Declared out of order; the controller figures it out.
+		ctrl.LaunchGroup(
+			member("api", 0, "cache", "db"),
+			member("cache", 2*time.Second, "db"),
+			member("db", time.Second))
+
+		ctrl.RequestStop(nil)
+		test.NoError(t, ctrl.Wait())
+
+		test.Eq(t, []string{
+			"start db",
+			"start cache",
+			"start api",
+			"stop api",
+			"stop cache",
+			"stop db",
+		}, events)
+	})
+}
+
+func TestDependsOnBuildErrors(t *testing.T) {
+	ctrl := newController(t)
+	defer ctrl.RequestStop(nil)
+
+	ctrl.Launch("db", withDummyStartStop())
+
+	t.Run("unknown", func(t *testing.T) {
+		defer testutil.WantPanic(t, `component build failed: component "api": depends on unknown component "cache"`)
+		ctrl.Launch("api", withDummyStartStop(), launch.WithDependsOn("db", "cache"))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		defer testutil.WantPanic(t, "component build failed: dependency cycle: a -> b -> a")
+		ctrl.LaunchGroup(
+			launch.Member("a", withDummyStartStop(), launch.WithDependsOn("b")),
+			launch.Member("b", withDummyStartStop(), launch.WithDependsOn("a")))
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		defer testutil.WantPanic(t, `component build failed: component "db": name already in use`)
+		ctrl.Launch("db", withDummyStartStop())
+	})
+}
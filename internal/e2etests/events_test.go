@@ -0,0 +1,108 @@
+package e2etests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control"
+)
+
+func TestControllerObserver(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var got []launch.Event
+		ctrl := launch.NewController(t.Context(),
+			launch.WithControllerObserver(func(ev launch.Event) { got = append(got, ev) }))
+
+		testErr := errors.New("nope")
+		ctrl.Launch("ok", withDummyStartStop())
+		ctrl.Launch("bad",
+			withDummyStartStop(),
+			launch.WithCheckReady(func(ctx context.Context) (bool, error) { return false, testErr }))
+		ctrl.Wait()
+		synctest.Wait()
+
+		for i, ev := range got {
+			test.Eq(t, uint64(i+1), ev.Seq)
+		}
+
+		type summary struct {
+			Kind      launch.EventKind
+			Component string
+			Stage     string
+		}
+		var kinds []summary
+		for _, ev := range got {
+			kinds = append(kinds, summary{ev.Kind, ev.Component, ev.Stage})
+		}
+		must.Eq(t, []summary{
+			{launch.EventLaunchRequested, "ok", ""},
+			{launch.EventControllerState, "", ""},
+			{launch.EventComponentStarted, "ok", ""},
+			{launch.EventComponentReady, "ok", ""},
+			{launch.EventLaunchRequested, "bad", ""},
+			{launch.EventComponentStarted, "bad", ""},
+			{launch.EventCheckReadyAttempt, "bad", ""},
+			{launch.EventErrorRecorded, "bad", "wait-ready"},
+			{launch.EventComponentFailed, "bad", "wait-ready"},
+			{launch.EventControllerState, "", ""},
+			{launch.EventShutdownStage, "bad", "impl"},
+			{launch.EventShutdownStage, "ok", "impl"},
+			{launch.EventControllerState, "", ""},
+		}, kinds)
+
+		test.ErrorIs(t, got[6].Err, testErr)
+		test.Eq(t, "Dying", got[9].State)
+		test.Eq(t, "Dead", got[12].State)
+	})
+}
+
+func TestEvents_StartupFinished(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var got []launch.EventKind
+		ctrl := launch.NewController(t.Context(),
+			launch.WithControllerObserver(func(ev launch.Event) { got = append(got, ev.Kind) }))
+
+		// Neither waiting nor time passing counts as the end of startup.
+		go ctrl.Wait()
+		synctest.Wait()
+		ctrl.Launch("ok", withDummyStartStop())
+		time.Sleep(time.Hour)
+		synctest.Wait()
+		test.SliceNotContains(t, got, launch.EventStartupFinished)
+		test.False(t, ctrl.Status().StartupFinished)
+
+		must.NoError(t, ctrl.FinishStartup())
+		synctest.Wait()
+		must.SliceNotEmpty(t, got)
+		test.Eq(t, launch.EventStartupFinished, got[len(got)-1])
+		test.True(t, ctrl.Status().StartupFinished)
+
+		ctrl.RequestStop(nil)
+		ctrl.Wait()
+	})
+}
+
+func TestController_AddObserver(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := launch.NewController(t.Context())
+		ctrl.Launch("before", withDummyStartStop())
+
+		var got []string
+		ctrl.AddObserver(func(ev launch.Event) {
+			if ev.Kind == launch.EventLaunchRequested {
+				got = append(got, ev.Component)
+			}
+		})
+		ctrl.Launch("after", withDummyStartStop())
+		ctrl.RequestStop(nil)
+		ctrl.Wait()
+		synctest.Wait()
+
+		test.Eq(t, []string{"after"}, got) // earlier events are missed
+	})
+}
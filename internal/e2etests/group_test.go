@@ -0,0 +1,94 @@
+package e2etests
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/spikesdivzero/launch-control"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
+
+func TestLaunchGroupStartsConcurrently(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		var mu sync.Mutex
+		events := []string{}
+		record := func(s string) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, s)
+		}
+
+		// Our members are all created at the same (synctest) instant as the LaunchGroup call.
+		member := func(name string, readyAfter time.Duration) launch.GroupMember {
+			t0 := time.Now()
+			return launch.Member(name,
+				launch.WithStartStop(
+					func(context.Context) error { return nil },
+					func(context.Context) error { record("stop " + name); return nil }),
+				launch.WithCheckReady(func(context.Context) (bool, error) {
+					return time.Since(t0) >= readyAfter, nil
+				}),
+				launch.WithCheckReadyBackoff(launch.ConstBackoff(time.Second)))
+		}
+
+		ctrl.Launch("first", launch.WithStartStop(
+			func(context.Context) error { return nil },
+			func(context.Context) error { record("stop first"); return nil }))
+
+		t0 := time.Now()
+		ctrl.LaunchGroup(
+			member("a", 3*time.Second),
+			member("b", 5*time.Second),
+			member("c", 2*time.Second))
+		test.Eq(t, 5*time.Second, time.Since(t0))
+
+		ctrl.Launch("last", launch.WithStartStop(
+			func(context.Context) error { return nil },
+			func(context.Context) error { record("stop last"); return nil }))
+
+		ctrl.RequestStop(nil)
+		test.NoError(t, ctrl.Wait())
+
+		// The group is stopped between its neighbors, in any order amongst its own members.
+		test.Len(t, 5, events)
+		test.Eq(t, "stop last", events[0])
+		test.Eq(t, "stop first", events[4])
+		groupStops := slices.Clone(events[1:4])
+		slices.Sort(groupStops)
+		test.Eq(t, []string{"stop a", "stop b", "stop c"}, groupStops)
+	})
+}
+
+func TestLaunchGroupMemberFailure(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		testErr := errors.New("no cache for you")
+		stopped := false
+
+		ctrl.LaunchGroup(
+			launch.Member("good", launch.WithStartStop(
+				func(context.Context) error { return nil },
+				func(context.Context) error { stopped = true; return nil })),
+			launch.Member("bad", launch.WithStartStop(
+				func(context.Context) error { return testErr },
+				func(context.Context) error { return nil })))
+
+		test.ErrorIs(t, ctrl.Wait(), testErr)
+		test.True(t, stopped)
+
+		// Launches after a failed group are discarded.
+		ctrl.Launch("discarded", launch.WithStartStop(
+			func(context.Context) error { panic("should not be started") },
+			func(context.Context) error { return nil }))
+		test.ErrorIs(t, ctrl.Err(), lcerrors.ComponentError{Name: "bad", Stage: "run exited", Err: testErr})
+	})
+}
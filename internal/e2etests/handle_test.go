@@ -0,0 +1,119 @@
+package e2etests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/spikesdivzero/launch-control"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+func TestComponentHandle(t *testing.T) {
+	t.Run("ready, then stopped", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			ctrl := newController(t)
+
+			h := ctrl.Launch("test", withDummyStartStop())
+			test.Eq(t, launch.ComponentReady, h.State())
+			test.True(t, h.Ready())
+			testutil.ChanReadIsBlocked(t, h.Done())
+
+			ctrl.RequestStop(nil)
+			<-h.Done()
+			test.Eq(t, launch.ComponentStopped, h.State())
+			test.False(t, h.Ready())
+			test.NoError(t, h.Err())
+			ctrl.Wait()
+		})
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			ctrl := newController(t)
+
+			testErr := errors.New("nope")
+			h := ctrl.Launch("test",
+				withDummyStartStop(),
+				launch.WithCheckReady(func(ctx context.Context) (bool, error) { return false, testErr }))
+			test.Eq(t, launch.ComponentFailed, h.State())
+			test.ErrorIs(t, h.Err(), testErr)
+
+			ctrl.Wait()
+			testutil.ChanReadIsClosed(t, h.Done())
+			test.Eq(t, launch.ComponentFailed, h.State()) // still failed, even after it was shut down
+		})
+	})
+
+	t.Run("run exits", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			ctrl := newController(t)
+
+			testErr := errors.New("boop")
+			h := ctrl.Launch("test", launch.WithRun(
+				func(ctx context.Context) error {
+					time.Sleep(time.Second)
+					return testErr
+				},
+				func(ctx context.Context) error { return nil }))
+
+			<-h.Done()
+			test.Eq(t, launch.ComponentFailed, h.State())
+			test.ErrorIs(t, h.Err(), testErr)
+			ctrl.Wait()
+		})
+	})
+
+	t.Run("stopped individually", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			ctrl := newController(t)
+
+			h := ctrl.Launch("test", withDummyStartStop())
+
+			test.NoError(t, ctrl.StopComponent(t.Context(), "test"))
+			<-h.Done()
+			test.Eq(t, launch.ComponentStopped, h.State())
+
+			ctrl.RequestStop(nil)
+			ctrl.Wait()
+		})
+	})
+
+	t.Run("abandoned", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			ctrl := newController(t)
+
+			releaseCh := make(chan struct{})
+			h := ctrl.Launch("test",
+				launch.WithRun(
+					func(ctx context.Context) error {
+						<-releaseCh // ignores both Shutdown and ctx
+						return nil
+					},
+					func(ctx context.Context) error { return nil }),
+				launch.WithShutdownCompletionTimeout(time.Second))
+
+			ctrl.RequestStop(nil)
+			ctrl.Wait()
+			testutil.ChanReadIsClosed(t, h.Done())
+			test.Eq(t, launch.ComponentAbandoned, h.State())
+
+			close(releaseCh)
+			synctest.Wait()
+		})
+	})
+
+	t.Run("discarded", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			ctrl := newController(t)
+			ctrl.RequestStop(nil)
+
+			h := ctrl.Launch("test", withDummyStartStop())
+			test.Eq(t, launch.ComponentStopped, h.State())
+			testutil.ChanReadIsClosed(t, h.Done())
+		})
+	})
+}
@@ -0,0 +1,69 @@
+package e2etests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control"
+)
+
+func TestControllerLogger(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var buf bytes.Buffer
+		log := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		ctrl := launch.NewController(t.Context(), launch.WithControllerLogger(log))
+
+		attempts := 0
+		ctrl.Launch("test",
+			withDummyStartStop(),
+			launch.WithCheckReady(func(ctx context.Context) (bool, error) {
+				attempts++
+				return attempts == 2, nil
+			}),
+			launch.WithCheckReadyBackoff(func() time.Duration { return time.Second }))
+		ctrl.RequestStop(nil)
+		must.NoError(t, ctrl.Wait())
+
+		type record struct {
+			Level     string
+			Msg       string
+			Component string
+			Stage     string
+			Attempt   int
+			Backoff   time.Duration
+			Duration  time.Duration
+		}
+		var got []record
+		for line := range bytes.Lines(buf.Bytes()) {
+			var r record
+			must.NoError(t, json.Unmarshal(line, &r))
+			r.Duration = r.Duration.Round(time.Second)
+			got = append(got, r)
+		}
+
+		test.Eq(t, []record{
+			{Level: "DEBUG", Msg: "launch requested", Component: "test"},
+			{Level: "INFO", Msg: "starting component", Component: "test", Stage: "startup"},
+			{Level: "DEBUG", Msg: "readiness check attempted", Component: "test", Stage: "wait-ready", Attempt: 1},
+			{Level: "DEBUG", Msg: "backing off before the next readiness check", Component: "test", Stage: "wait-ready",
+				Attempt: 1, Backoff: time.Second},
+			{Level: "DEBUG", Msg: "readiness check attempted", Component: "test", Stage: "wait-ready", Attempt: 2},
+			{Level: "INFO", Msg: "component ready", Component: "test", Stage: "wait-ready", Duration: time.Second},
+			{Level: "INFO", Msg: "controller state changed"},
+			{Level: "INFO", Msg: "stopping component", Component: "test"},
+			{Level: "INFO", Msg: "shutdown stage entered", Component: "test", Stage: "impl"},
+			{Level: "DEBUG", Msg: "shutdown stage finished", Component: "test", Stage: "impl"},
+			{Level: "DEBUG", Msg: "component shut down", Component: "test"},
+			{Level: "INFO", Msg: "component stopped", Component: "test"},
+			{Level: "INFO", Msg: "controller state changed"},
+			{Level: "INFO", Msg: "controller stopped", Duration: time.Second},
+		}, got)
+	})
+}
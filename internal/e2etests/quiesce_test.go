@@ -0,0 +1,47 @@
+package e2etests
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/spikesdivzero/launch-control"
+)
+
+// The component launched last (and so stopped first) stops taking on new work before anything is shut down.
+func TestQuiesce(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		var accepting atomic.Bool
+		var acceptingWhileDbStopping bool
+		var quiesceReason launch.ShutdownReason
+
+		ctrl.Launch("db", launch.WithStartStop(
+			func(ctx context.Context) error { return nil },
+			func(ctx context.Context) error {
+				acceptingWhileDbStopping = accepting.Load()
+				return nil
+			}))
+		ctrl.Launch("http",
+			launch.WithStartStop(
+				func(ctx context.Context) error { accepting.Store(true); return nil },
+				func(ctx context.Context) error { return nil }),
+			launch.WithQuiesce(func(ctx context.Context) error {
+				quiesceReason, _ = launch.ShutdownReasonFromContext(ctx)
+				accepting.Store(false)
+				return nil
+			}))
+
+		err := errors.New("hello")
+		time.AfterFunc(time.Second, func() { ctrl.RequestStop(err) })
+		test.ErrorIs(t, ctrl.Wait(), err)
+
+		test.False(t, acceptingWhileDbStopping)
+		test.ErrorIs(t, quiesceReason, err)
+	})
+}
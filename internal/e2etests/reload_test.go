@@ -0,0 +1,74 @@
+package e2etests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/synctest"
+
+	"github.com/shoenig/test"
+	"github.com/spikesdivzero/launch-control"
+)
+
+func TestReload(t *testing.T) {
+	// A config value that each component holds on to, with the prepared value kept aside until commit.
+	type config struct{ current, prepared string }
+	withConfig := func(cfg *config, next func() (string, error)) launch.ComponentOption {
+		return launch.WithBundledOptions(
+			withDummyStartStop(),
+			launch.WithReload(
+				func(context.Context) error {
+					v, err := next()
+					if err == nil {
+						cfg.prepared = v
+					}
+					return err
+				},
+				func(context.Context) error {
+					cfg.current, cfg.prepared = cfg.prepared, ""
+					return nil
+				}),
+			launch.WithReloadAbort(func(context.Context) error {
+				cfg.prepared = ""
+				return nil
+			}))
+	}
+
+	t.Run("all or nothing", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			ctrl := newController(t)
+
+			var a, b config
+			var bErr error
+			ctrl.Launch("a", withConfig(&a, func() (string, error) { return "v2", nil }))
+			ctrl.Launch("b", withConfig(&b, func() (string, error) { return "v2", bErr }))
+
+			bErr = errors.New("invalid config")
+			test.ErrorIs(t, ctrl.Reload(t.Context()), bErr)
+			test.Eq(t, config{}, a) // prepared, then aborted
+			test.Eq(t, config{}, b)
+
+			bErr = nil
+			test.NoError(t, ctrl.Reload(t.Context()))
+			test.Eq(t, config{current: "v2"}, a)
+			test.Eq(t, config{current: "v2"}, b)
+
+			// Neither reload has any bearing on the controller's health.
+			test.NoError(t, ctrl.Err())
+
+			ctrl.RequestStop(nil)
+			ctrl.Wait()
+		})
+	})
+
+	t.Run("after stopping", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			ctrl := newController(t)
+			ctrl.Launch("a", withDummyStartStop())
+			ctrl.RequestStop(nil)
+			ctrl.Wait()
+
+			test.Error(t, ctrl.Reload(t.Context()))
+		})
+	})
+}
@@ -0,0 +1,64 @@
+package e2etests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/synctest"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
+
+func TestReplace(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		var events []string
+		pool := func(version string, readyErr error) []launch.ComponentOption {
+			return []launch.ComponentOption{
+				launch.WithStartStop(
+					func(ctx context.Context) error {
+						events = append(events, "start "+version)
+						return nil
+					},
+					func(ctx context.Context) error {
+						events = append(events, "stop "+version)
+						return nil
+					}),
+				launch.WithCheckReady(func(ctx context.Context) (bool, error) {
+					synctest.Wait() // let Start finish, so the order of events is stable
+					return readyErr == nil, readyErr
+				}),
+			}
+		}
+
+		ctrl.Launch("pool", pool("v1", nil)...)
+		ctrl.Launch("api",
+			launch.WithStartStop(
+				func(ctx context.Context) error { return nil },
+				func(ctx context.Context) error {
+					events = append(events, "stop api")
+					return nil
+				}))
+
+		must.NoError(t, ctrl.Replace("pool", pool("v2", nil)...))
+		synctest.Wait()
+		test.Eq(t, []string{"start v1", "start v2", "stop v1"}, events)
+
+		// A failed replacement leaves the current one running.
+		testErr := errors.New("bad config")
+		err := ctrl.Replace("pool", pool("v3", testErr)...)
+		test.ErrorIs(t, err, testErr)
+		test.Eq(t, []string{"start v1", "start v2", "stop v1", "start v3", "stop v3"}, events)
+		test.ErrorIs(t, ctrl.Replace("nope", pool("v4", nil)...), lcerrors.ErrUnknownComponent)
+
+		// v2 took v1's place in the shutdown order.
+		events = nil
+		ctrl.RequestStop(nil)
+		test.NoError(t, ctrl.Wait())
+		test.Eq(t, []string{"stop api", "stop v2"}, events)
+	})
+}
@@ -0,0 +1,106 @@
+package e2etests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/spikesdivzero/launch-control"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
+
+func TestRestartOnFailure(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		testErr := errors.New("flaky")
+		runs := 0
+		stopCh := make(chan struct{})
+		ctrl.Launch("flaky",
+			launch.WithRun(
+				func(ctx context.Context) error {
+					runs++
+					if runs <= 2 {
+						time.Sleep(time.Second)
+						return testErr
+					}
+					<-stopCh
+					return nil
+				},
+				func(ctx context.Context) error {
+					close(stopCh)
+					return nil
+				}),
+			launch.WithRestartPolicy(launch.RestartOnFailure, 3, time.Minute),
+			launch.WithRestartBackoff(launch.ConstBackoff(5*time.Second)))
+
+		// Two failures, each followed by a restart after the backoff.
+		time.Sleep(time.Minute)
+		synctest.Wait()
+		test.Eq(t, 3, runs)
+
+		ctrl.RequestStop(nil)
+		test.ErrorIs(t, ctrl.Wait(), testErr)
+		test.Eq(t, []error{
+			lcerrors.ComponentError{Name: "flaky", Stage: "restart", Err: testErr},
+			lcerrors.ComponentError{Name: "flaky", Stage: "restart", Err: testErr},
+		}, ctrl.AllErrors())
+	})
+}
+
+func TestRestartBudgetExhausted(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		runs := 0
+		ctrl.Launch("quitter",
+			launch.WithRun(
+				func(ctx context.Context) error {
+					runs++
+					time.Sleep(time.Second)
+					return nil
+				},
+				func(ctx context.Context) error { return nil }),
+			launch.WithRestartPolicy(launch.RestartAlways, 2, time.Minute))
+
+		test.ErrorIs(t, ctrl.Wait(), lcerrors.ErrRunExited)
+		test.Eq(t, 3, runs)
+		test.Eq(t, []error{
+			lcerrors.ComponentError{Name: "quitter", Stage: "restart", Err: lcerrors.ErrRunExited},
+			lcerrors.ComponentError{Name: "quitter", Stage: "restart", Err: lcerrors.ErrRunExited},
+			lcerrors.ComponentError{Name: "quitter", Stage: "restart", Err: lcerrors.ErrRestartBudgetExhausted},
+		}, ctrl.AllErrors())
+	})
+}
+
+func TestRestartStartStop(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		testErr := errors.New("can't start")
+		starts, stops := 0, 0
+		ctrl.Launch("ssw",
+			launch.WithStartStop(
+				func(ctx context.Context) error {
+					if starts++; starts == 1 {
+						return testErr
+					}
+					return nil
+				},
+				func(ctx context.Context) error {
+					stops++
+					return nil
+				}),
+			launch.WithRestartPolicy(launch.RestartOnFailure, 1, 0))
+
+		time.Sleep(time.Minute)
+		synctest.Wait()
+		ctrl.RequestStop(nil)
+		test.ErrorIs(t, ctrl.Wait(), testErr)
+		test.Eq(t, 2, starts)
+		test.Eq(t, 1, stops)
+	})
+}
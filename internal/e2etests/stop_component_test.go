@@ -0,0 +1,61 @@
+package e2etests
+
+import (
+	"context"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
+
+func TestStopComponent(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := newController(t)
+
+		var mainStopped bool
+		ctrl.Launch("main",
+			launch.WithStartStop(
+				func(ctx context.Context) error { return nil },
+				func(ctx context.Context) error {
+					mainStopped = true
+					return nil
+				}))
+
+		workerRuns := 0
+		worker := func() []launch.ComponentOption {
+			return []launch.ComponentOption{
+				launch.WithRun(
+					func(ctx context.Context) error {
+						workerRuns++
+						<-ctx.Done()
+						return ctx.Err()
+					},
+					func(ctx context.Context) error { return nil }), // ignores the request; cancelled by ctx instead
+				launch.WithShutdownCompletionTimeout(time.Second),
+				launch.WithRestartPolicy(launch.RestartAlways, 0, 0),
+			}
+		}
+		ctrl.Launch("worker", worker()...)
+
+		must.NoError(t, ctrl.StopComponent(t.Context(), "worker"))
+		time.Sleep(time.Minute)
+		synctest.Wait()
+		test.Eq(t, 1, workerRuns) // not restarted
+		test.False(t, mainStopped)
+
+		// It's gone, but the name can be reused.
+		test.ErrorIs(t, ctrl.StopComponent(t.Context(), "worker"), lcerrors.ErrUnknownComponent)
+		ctrl.Launch("worker", worker()...)
+		synctest.Wait()
+		test.Eq(t, 2, workerRuns)
+
+		ctrl.RequestStop(nil)
+		ctrl.Wait()
+		test.True(t, mainStopped)
+		test.ErrorIs(t, ctrl.StopComponent(t.Context(), "main"), lcerrors.ErrControllerNotAlive)
+	})
+}
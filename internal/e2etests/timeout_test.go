@@ -115,3 +115,41 @@ func TestReadyCallTimeout(t *testing.T) {
 		time.Sleep(5 * time.Minute)
 	})
 }
+
+func TestControllerShutdownTimeout(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := launch.NewController(t.Context(), launch.WithControllerShutdownTimeout(10*time.Second))
+
+		flusherStopped := false
+		ctrl.Launch("flusher",
+			launch.WithStartStop(
+				func(ctx context.Context) error { return nil },
+				func(ctx context.Context) error {
+					flusherStopped = true
+					return nil
+				}),
+			launch.WithShutdownReserve(2*time.Second))
+		ctrl.Launch("app",
+			launch.WithRun(
+				func(ctx context.Context) error {
+					<-ctx.Done()
+					return nil
+				},
+				func(ctx context.Context) error {
+					time.Sleep(time.Minute)
+					return nil
+				}))
+
+		var stopAt time.Time
+		time.AfterFunc(time.Second, func() {
+			stopAt = time.Now()
+			ctrl.RequestStop(nil)
+		})
+		test.ErrorIs(t, ctrl.Wait(), lcerrors.ContextTimeoutError{Source: "ControllerShutdownTimeout"})
+		test.LessEq(t, 10*time.Second, time.Since(stopAt))
+		test.True(t, flusherStopped)
+
+		// Same as in TestShutdownCompletionTimeout.
+		time.Sleep(5 * time.Minute)
+	})
+}
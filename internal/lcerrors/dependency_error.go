@@ -0,0 +1,19 @@
+package lcerrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+type DependencyError struct {
+	Name string
+	Err  error
+}
+
+func (de DependencyError) Error() string {
+	return fmt.Sprintf("dependency %v: %v", de.Name, de.Err)
+}
+
+func (de DependencyError) Unwrap() error { return de.Err }
+
+func (de DependencyError) Is(target error) bool { return errors.Is(de.Err, target) }
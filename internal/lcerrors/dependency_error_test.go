@@ -0,0 +1,16 @@
+package lcerrors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shoenig/test"
+)
+
+func TestDependencyError_Basics(t *testing.T) {
+	err := error(DependencyError{"db", ErrDependencyNotReady})
+
+	test.Eq(t, "dependency db: failed to become ready", err.Error())
+	test.ErrorIs(t, err, ErrDependencyNotReady)
+	test.EqOp(t, ErrDependencyNotReady, errors.Unwrap(err))
+}
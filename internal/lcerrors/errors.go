@@ -14,4 +14,20 @@ var (
 
 var (
 	ErrShutdownAbandonedNonResponsive = errors.New("failed to respond to both ImplShutdown and ctx cancellation; abandoning it")
+	ErrShutdownBudgetExhausted        = errors.New("controller shutdown timeout expired before the component could be stopped")
+)
+
+var (
+	ErrDependencyNotReady = errors.New("failed to become ready")
+)
+
+var (
+	ErrRunExited              = errors.New("run exited without an error")
+	ErrRestartBudgetExhausted = errors.New("restart budget exhausted")
+)
+
+var (
+	ErrUnknownComponent   = errors.New("unknown component")
+	ErrControllerNotAlive = errors.New("controller is not alive")
+	ErrComponentStopped   = errors.New("component was stopped")
 )
@@ -0,0 +1,72 @@
+// Package sdnotify implements the client side of systemd's sd_notify protocol.
+//
+// See https://www.freedesktop.org/software/systemd/man/latest/sd_notify.html
+package sdnotify
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Ready    = "READY=1"
+	Stopping = "STOPPING=1"
+	Watchdog = "WATCHDOG=1"
+)
+
+func Status(s string) string {
+	return "STATUS=" + s
+}
+
+func MainPID(pid int) string {
+	return "MAINPID=" + strconv.Itoa(pid)
+}
+
+// A Notifier sends state updates to the service manager.
+type Notifier struct {
+	addr *net.UnixAddr
+}
+
+// Returns a Notifier for the socket named by $NOTIFY_SOCKET, or nil if it's not set (i.e. we're not running under
+// systemd, or the unit isn't Type=notify).
+func FromEnv() *Notifier {
+	path := os.Getenv("NOTIFY_SOCKET")
+	if path == "" {
+		return nil
+	}
+	// A leading '@' refers to the abstract namespace, which the net package already understands.
+	return &Notifier{&net.UnixAddr{Name: path, Net: "unixgram"}}
+}
+
+// Sends the given assignments, e.g. [Ready], as a single datagram.
+func (n *Notifier) Send(assignments ...string) error {
+	conn, err := net.DialUnix("unixgram", nil, n.addr)
+	if err != nil {
+		return fmt.Errorf("sd_notify: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(strings.Join(assignments, "\n"))); err != nil {
+		return fmt.Errorf("sd_notify: %w", err)
+	}
+	return nil
+}
+
+// Returns how often a [Watchdog] ping should be sent, based on $WATCHDOG_USEC and $WATCHDOG_PID, or zero if the
+// watchdog isn't enabled for this process.
+//
+// As recommended by systemd, this is half of the actual watchdog timeout.
+func WatchdogInterval() time.Duration {
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond / 2
+}
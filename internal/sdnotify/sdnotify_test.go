@@ -0,0 +1,79 @@
+package sdnotify
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+// Listens on a unixgram socket, and points $NOTIFY_SOCKET at it.
+func listen(t *testing.T) *net.UnixConn {
+	// Socket paths are limited to ~100 bytes, which t.TempDir can exceed.
+	dir, err := os.MkdirTemp("", "sdnotify")
+	must.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	must.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func TestNotifier_Send(t *testing.T) {
+	conn := listen(t)
+
+	n := FromEnv()
+	must.NotNil(t, n)
+	must.NoError(t, n.Send(Ready, Status("Running")))
+
+	buf := make([]byte, 256)
+	must.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	size, err := conn.Read(buf)
+	must.NoError(t, err)
+	test.Eq(t, "READY=1\nSTATUS=Running", string(buf[:size]))
+}
+
+func TestNotifier_Send_noListener(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(t.TempDir(), "missing.sock"))
+	test.ErrorContains(t, FromEnv().Send(Ready), "sd_notify: ")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	test.Nil(t, FromEnv())
+
+	t.Setenv("NOTIFY_SOCKET", "@abstract")
+	test.Eq(t, "@abstract", FromEnv().addr.Name)
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	tests := []struct {
+		name string
+		usec string
+		pid  string
+		want time.Duration
+	}{
+		{"unset", "", "", 0},
+		{"invalid", "soon", "", 0},
+		{"zero", "0", "", 0},
+		{"no pid", "10000000", "", 5 * time.Second},
+		{"our pid", "10000000", strconv.Itoa(os.Getpid()), 5 * time.Second},
+		{"another pid", "10000000", "1", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WATCHDOG_USEC", tt.usec)
+			t.Setenv("WATCHDOG_PID", tt.pid)
+			test.Eq(t, tt.want, WatchdogInterval())
+		})
+	}
+}
@@ -2,6 +2,7 @@ package testutil
 
 import (
 	"context"
+	"log/slog"
 	"time"
 )
 
@@ -31,8 +32,11 @@ type MockComponent struct {
 	Recorder struct {
 		Connect struct {
 			Called           bool
+			Log              *slog.Logger
 			LogError         func(string, error)
 			NotifyOnExited   func(error)
+			NotifyCheckReady func(int, bool, error)
+			NotifyShutdown   func(string)
 			AsyncGracePeriod time.Duration
 		}
 		Start struct {
@@ -52,14 +56,20 @@ type MockComponent struct {
 }
 
 func (mc *MockComponent) ConnectController(
+	log *slog.Logger,
 	logError func(string, error),
 	notifyOnExited func(error),
+	notifyCheckReady func(int, bool, error),
+	notifyShutdown func(string),
 	asyncGracePeriod time.Duration,
 ) {
 	rc := &mc.Recorder.Connect
 	rc.Called = true
+	rc.Log = log
 	rc.LogError = logError
 	rc.NotifyOnExited = notifyOnExited
+	rc.NotifyCheckReady = notifyCheckReady
+	rc.NotifyShutdown = notifyShutdown
 	rc.AsyncGracePeriod = asyncGracePeriod
 }
 
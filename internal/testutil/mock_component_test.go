@@ -2,6 +2,7 @@ package testutil
 
 import (
 	"errors"
+	"log/slog"
 	"testing"
 	"testing/synctest"
 	"time"
@@ -23,7 +24,15 @@ func TestMockComponent_ConnectController(t *testing.T) {
 	}
 
 	mc := &MockComponent{}
-	mc.ConnectController(testLogError, testNotify, 871*time.Millisecond)
+	var testCheckReadyGot int
+	testCheckReady := func(attempt int, ready bool, err error) { testCheckReadyGot = attempt }
+
+	var testShutdownGot string
+	testShutdown := func(stage string) { testShutdownGot = stage }
+
+	testLog := slog.New(slog.DiscardHandler)
+	mc.ConnectController(testLog, testLogError, testNotify, testCheckReady, testShutdown, 871*time.Millisecond)
+	test.Eq(t, testLog, mc.Recorder.Connect.Log)
 
 	testErr := errors.New("boop")
 	mc.Recorder.Connect.NotifyOnExited(testErr)
@@ -33,6 +42,12 @@ func TestMockComponent_ConnectController(t *testing.T) {
 	test.Eq(t, testLogErrorGot.stage, "in-test")
 	test.ErrorIs(t, testLogErrorGot.err, testErr)
 
+	mc.Recorder.Connect.NotifyCheckReady(4, false, nil)
+	test.Eq(t, 4, testCheckReadyGot)
+
+	mc.Recorder.Connect.NotifyShutdown("context")
+	test.Eq(t, "context", testShutdownGot)
+
 	test.Eq(t, 871*time.Millisecond, mc.Recorder.Connect.AsyncGracePeriod)
 }
 
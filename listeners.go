@@ -0,0 +1,159 @@
+package launch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// Inherited listeners, along with those opened via Listen, are a property of the process, rather than of any one
+// controller.
+var inherited struct {
+	once      sync.Once
+	listeners map[string]net.Listener
+	err       error
+
+	mu      sync.Mutex
+	parsed  bool
+	opened  map[string]net.Listener
+	claimed map[string]bool
+	closed  bool
+}
+
+// Listeners returns the listeners inherited from systemd's socket activation (or a parent process, see
+// [Controller.Upgrade]), keyed by name.
+//
+// The listeners are described by the $LISTEN_PID, $LISTEN_FDS, and $LISTEN_FDNAMES environment variables, as per
+// sd_listen_fds(3). Names come from `FileDescriptorName=` in the socket unit, and so must be unique. The variables
+// are parsed (and then unset, so they aren't passed on to child processes) on the first call, with later calls
+// returning the same result. If there are no inherited listeners, the map is empty, as it always is on platforms
+// other than unix.
+//
+// Inherited listeners are meant to be handed to components via [WithListener]. Any that haven't been claimed by
+// a component by the time startup finishes (see [Controller.FinishStartup]) are closed. To open a listener when
+// it wasn't inherited, see [Listen].
+func Listeners() (map[string]net.Listener, error) {
+	inherited.once.Do(func() {
+		inherited.listeners, inherited.err = parseListeners(os.Getenv, os.Getpid(), listenFDsStart)
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+
+		inherited.mu.Lock()
+		inherited.parsed = true
+		inherited.mu.Unlock()
+	})
+	return inherited.listeners, inherited.err
+}
+
+// Listen returns the named listener, opening it via [net.Listen] if it wasn't inherited (see [Listeners]).
+//
+// Listeners opened this way are treated the same as inherited ones: they're meant to be handed to components via
+// [WithListener], are closed if that hasn't happened by the time startup finishes, and are passed on to the new
+// process by [Controller.Upgrade]. There, the same call picks up the inherited listener instead of opening a new
+// one, so network and address are only used when nothing by that name was inherited.
+//
+// Later calls with the same name return the same listener. It's an error to open a listener once startup has
+// finished.
+func Listen(name, network, address string) (net.Listener, error) {
+	listeners, err := Listeners()
+	if err != nil {
+		return nil, err
+	}
+	if ln, ok := listeners[name]; ok {
+		return ln, nil
+	}
+
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+
+	if ln, ok := inherited.opened[name]; ok {
+		return ln, nil
+	}
+	if inherited.closed {
+		return nil, fmt.Errorf("listener %q can't be opened, as startup has already finished", name)
+	}
+
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("listener %q: %w", name, err)
+	}
+	if inherited.opened == nil {
+		inherited.opened = map[string]net.Listener{}
+	}
+	inherited.opened[name] = ln
+	return ln, nil
+}
+
+// Returns the named listener, whether inherited or opened via Listen. Requires inherited.mu to be held.
+func lookupListener(name string) (net.Listener, bool) {
+	if ln, ok := inherited.listeners[name]; ok {
+		return ln, true
+	}
+	ln, ok := inherited.opened[name]
+	return ln, ok
+}
+
+// Claims the named listener, so that it isn't closed once startup finishes.
+func claimListener(name string) (net.Listener, error) {
+	if _, err := Listeners(); err != nil {
+		return nil, err
+	}
+
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+
+	ln, ok := lookupListener(name)
+	if !ok {
+		return nil, fmt.Errorf("no inherited or opened listener named %q", name)
+	}
+	if inherited.closed && !inherited.claimed[name] {
+		return nil, fmt.Errorf("listener %q was closed, as it wasn't claimed during startup", name)
+	}
+	if inherited.claimed == nil {
+		inherited.claimed = map[string]bool{}
+	}
+	inherited.claimed[name] = true
+	return ln, nil
+}
+
+// Closes the listeners that weren't claimed, if the inherited ones were ever parsed.
+func closeUnclaimedListeners() error {
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+
+	if inherited.closed || !inherited.parsed {
+		return nil
+	}
+	inherited.closed = true
+
+	var errs []error
+	for _, listeners := range []map[string]net.Listener{inherited.listeners, inherited.opened} {
+		for name, ln := range listeners {
+			if !inherited.claimed[name] {
+				if err := ln.Close(); err != nil {
+					errs = append(errs, fmt.Errorf("listener %q: %w", name, err))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type listenersCtxKey struct{}
+
+// ListenerFromContext returns the named listener given to the component via [WithListener], if any.
+//
+// The context passed to the component's `Run` (or `Start`) carries the listener.
+func ListenerFromContext(ctx context.Context, name string) (net.Listener, bool) {
+	listeners, _ := ctx.Value(listenersCtxKey{}).(map[string]net.Listener)
+	ln, ok := listeners[name]
+	return ln, ok
+}
+
+func contextWithListeners(ctx context.Context, listeners map[string]net.Listener) context.Context {
+	return context.WithValue(ctx, listenersCtxKey{}, listeners)
+}
@@ -0,0 +1,278 @@
+package launch
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"syscall"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+// Opens a listener per name, with their fds placed consecutively from firstFD (as systemd would from fd 3).
+func inheritListeners(t *testing.T, firstFD int, names ...string) map[string]string {
+	addrs := map[string]string{}
+	for i, name := range names {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		must.NoError(t, err)
+		addrs[name] = ln.Addr().String()
+
+		f, err := ln.(*net.TCPListener).File()
+		must.NoError(t, err)
+		must.NoError(t, syscall.Dup3(int(f.Fd()), firstFD+i, 0))
+		f.Close()
+		ln.Close()
+		t.Cleanup(func() { syscall.Close(firstFD + i) })
+	}
+	return addrs
+}
+
+// Swaps out the process-wide listener state for the duration of the test.
+func resetInherited(t *testing.T, listeners map[string]net.Listener) {
+	t.Cleanup(func() {
+		for _, ln := range inherited.opened {
+			ln.Close()
+		}
+		inherited.listeners, inherited.err = nil, nil
+		inherited.parsed, inherited.opened, inherited.claimed, inherited.closed = false, nil, nil, false
+	})
+	inherited.once.Do(func() {}) // never parse the real environment
+	inherited.listeners, inherited.err = listeners, nil
+	inherited.parsed, inherited.opened, inherited.claimed, inherited.closed = true, nil, nil, false
+}
+
+func Test_parseListeners(t *testing.T) {
+	const pid, firstFD = 1234, 200
+	env := func(vars map[string]string) func(string) string {
+		return func(k string) string { return vars[k] }
+	}
+
+	t.Run("not for us", func(t *testing.T) {
+		got, err := parseListeners(env(map[string]string{"LISTEN_PID": "99", "LISTEN_FDS": "1"}), pid, firstFD)
+		must.NoError(t, err)
+		test.MapEmpty(t, got)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		got, err := parseListeners(env(nil), pid, firstFD)
+		must.NoError(t, err)
+		test.MapEmpty(t, got)
+	})
+
+	t.Run("named", func(t *testing.T) {
+		addrs := inheritListeners(t, firstFD, "http", "grpc")
+		got, err := parseListeners(env(map[string]string{
+			"LISTEN_PID":     strconv.Itoa(pid),
+			"LISTEN_FDS":     "2",
+			"LISTEN_FDNAMES": "http:grpc",
+		}), pid, firstFD)
+		must.NoError(t, err)
+		must.MapLen(t, 2, got)
+		test.Eq(t, addrs["http"], got["http"].Addr().String())
+		test.Eq(t, addrs["grpc"], got["grpc"].Addr().String())
+		for _, ln := range got {
+			ln.Close()
+		}
+	})
+
+	t.Run("from upgrade", func(t *testing.T) {
+		addrs := inheritListeners(t, firstFD, "http")
+		got, err := parseListeners(env(map[string]string{
+			"LISTEN_FDS":      "1",
+			"LISTEN_FDNAMES":  "http",
+			upgradeReadyFDEnv: "201",
+		}), pid, firstFD)
+		must.NoError(t, err)
+		must.MapLen(t, 1, got)
+		test.Eq(t, addrs["http"], got["http"].Addr().String())
+		got["http"].Close()
+	})
+
+	t.Run("unnamed", func(t *testing.T) {
+		inheritListeners(t, firstFD, "x")
+		got, err := parseListeners(env(map[string]string{"LISTEN_PID": strconv.Itoa(pid), "LISTEN_FDS": "1"}),
+			pid, firstFD)
+		must.NoError(t, err)
+		must.MapContainsKey(t, got, "unknown")
+		got["unknown"].Close()
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			fds     string
+			names   string
+			wantErr string
+		}{
+			{"bad count", "two", "", `invalid LISTEN_FDS "two"`},
+			{"name count", "2", "a", "LISTEN_FDNAMES has 1 names, expected 2"},
+			{"duplicate names", "2", "a:a", `inherited listener name "a" is used more than once`},
+			{"not a socket", "3", "a:b:c", `inherited listener "c" (fd 202)`},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				inheritListeners(t, firstFD, "a", "b") // parsing takes ownership of the fds
+				got, err := parseListeners(env(map[string]string{
+					"LISTEN_PID":     strconv.Itoa(pid),
+					"LISTEN_FDS":     tt.fds,
+					"LISTEN_FDNAMES": tt.names,
+				}), pid, firstFD)
+				test.ErrorContains(t, err, tt.wantErr)
+				test.MapEmpty(t, got)
+			})
+		}
+	})
+}
+
+func TestWithListener(t *testing.T) {
+	newListener := func(t *testing.T) net.Listener {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		must.NoError(t, err)
+		t.Cleanup(func() { ln.Close() })
+		return ln
+	}
+
+	nopRun := WithRun(
+		func(context.Context) error { return nil },
+		func(context.Context) error { return nil })
+
+	t.Run("panics on empty name", func(t *testing.T) {
+		defer testutil.WantPanic(t, "WithListener: name must not be empty")
+		WithListener("")
+	})
+
+	t.Run("unknown name", func(t *testing.T) {
+		resetInherited(t, map[string]net.Listener{})
+		_, err := buildComponent("test", nopRun, WithListener("http"))
+		test.EqError(t, err, `WithListener: no inherited or opened listener named "http"`)
+	})
+
+	t.Run("claimed and passed via ctx", func(t *testing.T) {
+		httpLn, adminLn := newListener(t), newListener(t)
+		resetInherited(t, map[string]net.Listener{"http": httpLn, "admin": adminLn})
+
+		var got net.Listener
+		c, err := buildComponent("test", WithListener("http"), WithRun(
+			func(ctx context.Context) error {
+				got, _ = ListenerFromContext(ctx, "http")
+				_, ok := ListenerFromContext(ctx, "admin")
+				test.False(t, ok)
+				return nil
+			},
+			func(context.Context) error { return nil }))
+		must.NoError(t, err)
+		must.NoError(t, c.ImplRun(t.Context()))
+		test.Eq(t, httpLn, got)
+
+		// Only the unclaimed listener is closed once startup finishes.
+		must.NoError(t, closeUnclaimedListeners())
+		_, err = adminLn.Accept()
+		test.ErrorIs(t, err, net.ErrClosed)
+		test.Eq(t, httpLn.Addr(), got.Addr())
+
+		_, err = buildComponent("late", nopRun, WithListener("admin"))
+		test.ErrorContains(t, err, `"admin" was closed, as it wasn't claimed during startup`)
+
+		// Previously claimed listeners may still be handed out, e.g. for Replace.
+		_, err = buildComponent("replacement", nopRun, WithListener("http"))
+		test.NoError(t, err)
+	})
+
+	t.Run("closed once startup finishes", func(t *testing.T) {
+		httpLn, adminLn, unusedLn := newListener(t), newListener(t), newListener(t)
+		resetInherited(t, map[string]net.Listener{"http": httpLn, "admin": adminLn, "unused": unusedLn})
+
+		synctest.Test(t, func(t *testing.T) {
+			ctrl := NewController(t.Context())
+
+			// Waiting before anything is launched mustn't close the listeners early.
+			waitCh := make(chan error)
+			go func() { waitCh <- ctrl.Wait() }()
+			synctest.Wait()
+			ctrl.Launch("http", WithListener("http"), WithStartStop(
+				func(context.Context) error { return nil },
+				func(context.Context) error { return nil }))
+
+			// Nor must a slow step between launches.
+			time.Sleep(time.Hour)
+			ctrl.Launch("admin", WithListener("admin"), WithStartStop(
+				func(context.Context) error { return nil },
+				func(context.Context) error { return nil }))
+
+			must.NoError(t, ctrl.FinishStartup())
+			ctrl.RequestStop(nil)
+			must.NoError(t, <-waitCh)
+		})
+		_, err := unusedLn.Accept()
+		test.ErrorIs(t, err, net.ErrClosed)
+	})
+}
+
+func TestListen(t *testing.T) {
+	t.Run("opens", func(t *testing.T) {
+		resetInherited(t, map[string]net.Listener{})
+
+		ln, err := Listen("http", "tcp", "127.0.0.1:0")
+		must.NoError(t, err)
+		again, err := Listen("http", "tcp", "127.0.0.1:0")
+		must.NoError(t, err)
+		test.Eq(t, ln, again)
+
+		c, err := buildComponent("test", WithListener("http"), WithRun(
+			func(ctx context.Context) error {
+				got, _ := ListenerFromContext(ctx, "http")
+				test.Eq(t, ln, got)
+				return nil
+			},
+			func(context.Context) error { return nil }))
+		must.NoError(t, err)
+		must.NoError(t, c.ImplRun(t.Context()))
+	})
+
+	t.Run("prefers inherited", func(t *testing.T) {
+		inheritedLn, err := net.Listen("tcp", "127.0.0.1:0")
+		must.NoError(t, err)
+		t.Cleanup(func() { inheritedLn.Close() })
+		resetInherited(t, map[string]net.Listener{"http": inheritedLn})
+
+		ln, err := Listen("http", "tcp", "127.0.0.1:0")
+		must.NoError(t, err)
+		test.Eq(t, inheritedLn, ln)
+		test.MapEmpty(t, inherited.opened)
+	})
+
+	t.Run("closed once startup finishes", func(t *testing.T) {
+		resetInherited(t, map[string]net.Listener{})
+
+		httpLn, err := Listen("http", "tcp", "127.0.0.1:0")
+		must.NoError(t, err)
+		unusedLn, err := Listen("unused", "tcp", "127.0.0.1:0")
+		must.NoError(t, err)
+		_, err = claimListener("http")
+		must.NoError(t, err)
+
+		must.NoError(t, closeUnclaimedListeners())
+		_, err = unusedLn.Accept()
+		test.ErrorIs(t, err, net.ErrClosed)
+		test.NoError(t, httpLn.(*net.TCPListener).SetDeadline(time.Now()))
+
+		_, err = Listen("late", "tcp", "127.0.0.1:0")
+		test.EqError(t, err, `listener "late" can't be opened, as startup has already finished`)
+	})
+
+	t.Run("listen fails", func(t *testing.T) {
+		resetInherited(t, map[string]net.Listener{})
+		_, err := Listen("http", "bogus", "")
+		test.EqError(t, err, `listener "http": listen bogus: unknown network bogus`)
+	})
+}
+
+func TestListenerFromContext(t *testing.T) {
+	_, ok := ListenerFromContext(t.Context(), "http")
+	test.False(t, ok)
+}
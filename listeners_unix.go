@@ -0,0 +1,67 @@
+//go:build unix
+
+package launch
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// The first file descriptor passed by systemd, per sd_listen_fds(3).
+const listenFDsStart = 3
+
+func parseListeners(getenv func(string) string, pid, firstFD int) (map[string]net.Listener, error) {
+	listeners := map[string]net.Listener{}
+	switch lp := getenv("LISTEN_PID"); {
+	case lp == strconv.Itoa(pid):
+	case lp == "" && getenv("LISTEN_FDS") != "" && getenv(upgradeReadyFDEnv) != "":
+		// Passed on by Controller.Upgrade, which can't know our pid in advance.
+	default:
+		return listeners, nil // not meant for us
+	}
+
+	count, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || count < 0 {
+		return listeners, fmt.Errorf("invalid LISTEN_FDS %q", getenv("LISTEN_FDS"))
+	}
+
+	var names []string
+	if s := getenv("LISTEN_FDNAMES"); s != "" {
+		names = strings.Split(s, ":")
+	}
+	if names != nil && len(names) != count {
+		return listeners, fmt.Errorf("LISTEN_FDNAMES has %d names, expected %d", len(names), count)
+	}
+
+	fail := func(err error) (map[string]net.Listener, error) {
+		for _, ln := range listeners {
+			ln.Close()
+		}
+		return map[string]net.Listener{}, err
+	}
+
+	for i := range count {
+		name := "unknown" // same as sd_listen_fds_with_names
+		if names != nil {
+			name = names[i]
+		}
+		if _, ok := listeners[name]; ok {
+			return fail(fmt.Errorf("inherited listener name %q is used more than once", name))
+		}
+
+		fd := firstFD + i
+		syscall.CloseOnExec(fd)
+		f := os.NewFile(uintptr(fd), name)
+		ln, err := net.FileListener(f)
+		f.Close() // FileListener holds its own copy of the fd
+		if err != nil {
+			return fail(fmt.Errorf("inherited listener %q (fd %d): %w", name, fd, err))
+		}
+		listeners[name] = ln
+	}
+	return listeners, nil
+}
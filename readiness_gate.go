@@ -0,0 +1,148 @@
+package launch
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The defaults for [ReadinessGateOptions].
+const (
+	defaultReadinessGatePollInterval   = 100 * time.Millisecond
+	defaultReadinessGateShutdownMargin = time.Second
+)
+
+// ReadinessGateOptions configures a [ReadinessGate].
+type ReadinessGateOptions struct {
+	// The longest the gate waits for traffic to drain, once it's closed. This should cover the time it takes for
+	// load balancers to notice that the application is no longer ready.
+	DrainPeriod time.Duration
+
+	// Optional. Reports the number of requests in flight, e.g. from a counter kept by an HTTP middleware. If given,
+	// the drain ends as soon as it reaches zero (but no sooner than MinDrain), even if the DrainPeriod hasn't passed
+	// yet.
+	InFlight func() int
+
+	// The shortest the drain may be cut by InFlight reaching zero. Nothing being in flight at the moment says
+	// nothing about whether the load balancers have noticed that the gate is closed, so without this, the drain
+	// usually ends straight away, and new requests keep arriving while the servers are stopped. Set it to the time
+	// it takes for the load balancers to notice, and DrainPeriod to that plus the time it takes for the slowest
+	// requests to finish.
+	//
+	// Zero is only safe if the load balancers stop sending traffic as soon as the gate closes. Capped at
+	// DrainPeriod.
+	MinDrain time.Duration
+
+	// How often InFlight is checked. If zero, it defaults to 100ms.
+	PollInterval time.Duration
+
+	// The drain is cut short once less than this is left of the time given to the gate's shutdown (see
+	// [WithControllerShutdownTimeout] and [WithShutdownCompletionTimeout]), leaving time for the components stopped
+	// after it. If zero, it defaults to 1 second.
+	ShutdownMargin time.Duration
+}
+
+// A Gate is a built-in component that tracks whether the application should be taking on new traffic. See
+// [ReadinessGate].
+type Gate struct {
+	opts ReadinessGateOptions
+
+	open     atomic.Bool
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// ReadinessGate returns a gate which is open while its component is running, and drains traffic when it's stopped.
+// Launch it last, so that it's the first to be stopped, while the servers behind it are still running:
+//
+//	gate := launch.ReadinessGate(launch.ReadinessGateOptions{DrainPeriod: 5 * time.Second})
+//	// ... launch the servers, with readiness checks that use gate.IsOpen ...
+//	ctrl.Launch("readiness-gate", gate.Component())
+//
+// Starting the component opens the gate. Stopping it closes the gate, and then holds off the rest of the shutdown
+// until either the DrainPeriod has passed, or InFlight reaches zero once MinDrain has passed, whichever comes first.
+//
+// A Gate may only be launched once.
+func ReadinessGate(opts ReadinessGateOptions) *Gate {
+	if opts.DrainPeriod < 0 {
+		panic("ReadinessGate: DrainPeriod must not be negative")
+	}
+	if opts.MinDrain < 0 {
+		panic("ReadinessGate: MinDrain must not be negative")
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultReadinessGatePollInterval
+	}
+	if opts.ShutdownMargin <= 0 {
+		opts.ShutdownMargin = defaultReadinessGateShutdownMargin
+	}
+
+	return &Gate{
+		opts:   opts,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Component returns the options for the gate's component, to be passed to [Controller.Launch].
+func (g *Gate) Component() ComponentOption {
+	return WithRun(g.run, g.shutdown)
+}
+
+// IsOpen reports whether the gate is open, i.e. whether the application should be taking on new traffic. It's meant
+// to be used by readiness checks, such as those of an application's own health handlers.
+func (g *Gate) IsOpen() bool {
+	return g.open.Load()
+}
+
+func (g *Gate) run(ctx context.Context) error {
+	g.open.Store(true)
+	defer g.open.Store(false)
+
+	select {
+	case <-g.stopCh:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func (g *Gate) shutdown(ctx context.Context) error {
+	g.open.Store(false)
+	g.drain(ctx)
+	g.stopOnce.Do(func() { close(g.stopCh) })
+	return nil
+}
+
+func (g *Gate) drain(ctx context.Context) {
+	wait := g.opts.DrainPeriod
+	if deadline, ok := ctx.Deadline(); ok {
+		wait = min(wait, time.Until(deadline)-g.opts.ShutdownMargin)
+	}
+	if wait <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	minDrainEnd := time.Now().Add(g.opts.MinDrain)
+
+	var pollCh <-chan time.Time
+	if g.opts.InFlight != nil {
+		ticker := time.NewTicker(g.opts.PollInterval)
+		defer ticker.Stop()
+		pollCh = ticker.C
+	}
+
+	for {
+		if g.opts.InFlight != nil && !time.Now().Before(minDrainEnd) && g.opts.InFlight() <= 0 {
+			return
+		}
+		select {
+		case <-timer.C:
+			return
+		case <-ctx.Done():
+			return
+		case <-pollCh:
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package launch
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/spikesdivzero/launch-control/internal/testutil"
+)
+
+func TestReadinessGate(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		g := ReadinessGate(ReadinessGateOptions{})
+		test.Eq(t, ReadinessGateOptions{PollInterval: 100 * time.Millisecond, ShutdownMargin: time.Second}, g.opts)
+	})
+
+	t.Run("panics on negative drain period", func(t *testing.T) {
+		defer testutil.WantPanic(t, "ReadinessGate: DrainPeriod must not be negative")
+		ReadinessGate(ReadinessGateOptions{DrainPeriod: -1})
+	})
+
+	t.Run("panics on negative min drain", func(t *testing.T) {
+		defer testutil.WantPanic(t, "ReadinessGate: MinDrain must not be negative")
+		ReadinessGate(ReadinessGateOptions{MinDrain: -1})
+	})
+
+	t.Run("open while running", func(t *testing.T) {
+		synctest.Test(t, func(t *testing.T) {
+			ctrl := NewController(t.Context())
+			g := ReadinessGate(ReadinessGateOptions{DrainPeriod: 5 * time.Second})
+			test.False(t, g.IsOpen())
+
+			var openWhileStopping bool
+			ctrl.Launch("server", WithStartStop(
+				func(ctx context.Context) error { return nil },
+				func(ctx context.Context) error {
+					openWhileStopping = g.IsOpen()
+					return nil
+				}))
+			ctrl.Launch("gate", g.Component())
+			synctest.Wait()
+			test.True(t, g.IsOpen())
+
+			start := time.Now()
+			ctrl.RequestStop(nil)
+			synctest.Wait()
+			test.False(t, g.IsOpen()) // closed before the drain
+
+			test.NoError(t, ctrl.Wait())
+			test.False(t, openWhileStopping)
+			test.GreaterEq(t, 5*time.Second, time.Since(start))
+		})
+	})
+}
+
+func TestGate_drain(t *testing.T) {
+	var inFlight atomic.Int64
+	tests := []struct {
+		name     string
+		opts     ReadinessGateOptions
+		timeout  time.Duration
+		inFlight func()
+		want     time.Duration
+	}{
+		{"drain period", ReadinessGateOptions{DrainPeriod: 5 * time.Second}, 0, nil, 5 * time.Second},
+		{"no drain period", ReadinessGateOptions{}, 0, nil, 0},
+		{
+			"in flight reaches zero",
+			ReadinessGateOptions{DrainPeriod: 5 * time.Second, InFlight: func() int { return int(inFlight.Load()) }},
+			0,
+			func() {
+				inFlight.Store(2)
+				time.AfterFunc(2050*time.Millisecond, func() { inFlight.Store(0) })
+			},
+			2100 * time.Millisecond, // noticed on the next poll
+		},
+		{
+			"in flight already zero",
+			ReadinessGateOptions{DrainPeriod: 5 * time.Second, InFlight: func() int { return 0 }},
+			0,
+			nil,
+			0,
+		},
+		{
+			"in flight zero, min drain",
+			ReadinessGateOptions{
+				DrainPeriod: 5 * time.Second,
+				MinDrain:    2 * time.Second,
+				InFlight:    func() int { return 0 },
+			},
+			0,
+			nil,
+			2 * time.Second,
+		},
+		{
+			"in flight reaches zero after min drain",
+			ReadinessGateOptions{
+				DrainPeriod: 5 * time.Second,
+				MinDrain:    time.Second,
+				InFlight:    func() int { return int(inFlight.Load()) },
+			},
+			0,
+			func() {
+				inFlight.Store(2)
+				time.AfterFunc(2050*time.Millisecond, func() { inFlight.Store(0) })
+			},
+			2100 * time.Millisecond,
+		},
+		{
+			"min drain past drain period",
+			ReadinessGateOptions{
+				DrainPeriod: 3 * time.Second,
+				MinDrain:    10 * time.Second,
+				InFlight:    func() int { return 0 },
+			},
+			0,
+			nil,
+			3 * time.Second,
+		},
+		{"shutdown margin", ReadinessGateOptions{DrainPeriod: 5 * time.Second}, 4 * time.Second, nil, 3 * time.Second},
+		{"shutdown nearly spent", ReadinessGateOptions{DrainPeriod: 5 * time.Second}, time.Second, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				g := ReadinessGate(tt.opts)
+				ctx := t.Context()
+				if tt.timeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+					defer cancel()
+				}
+				if tt.inFlight != nil {
+					tt.inFlight()
+				}
+
+				start := time.Now()
+				g.drain(ctx)
+				test.Eq(t, tt.want, time.Since(start))
+			})
+		})
+	}
+}
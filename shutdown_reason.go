@@ -0,0 +1,100 @@
+package launch
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spikesdivzero/launch-control/internal/component"
+	"github.com/spikesdivzero/launch-control/internal/controller"
+)
+
+// A ShutdownTrigger says what started the controller's shutdown. See [ShutdownReason].
+type ShutdownTrigger int
+
+const (
+	// [Controller.RequestStop] was called (including by [AdminHandler], [WithControlSocket], and
+	// [Controller.Upgrade]), or the context passed to [NewController] was cancelled.
+	ShutdownRequested = ShutdownTrigger(controller.ShutdownRequested)
+
+	// One of the signals given to [WithSignals] was received.
+	ShutdownSignal = ShutdownTrigger(controller.ShutdownSignal)
+
+	// A critical component's `Run` exited, with or without an error.
+	ShutdownComponentExited = ShutdownTrigger(controller.ShutdownComponentExited)
+
+	// A critical component failed to start or become ready.
+	ShutdownComponentFailed = ShutdownTrigger(controller.ShutdownComponentFailed)
+
+	// A critical component failed because one of its timeouts expired, or the context passed to [NewController]
+	// reached its deadline.
+	ShutdownTimeout = ShutdownTrigger(controller.ShutdownTimeout)
+)
+
+func (t ShutdownTrigger) String() string {
+	return controller.ShutdownTrigger(t).String()
+}
+
+// A ShutdownReason explains why the controller is shutting down. Only the first stop request counts.
+//
+// While the controller is shutting down, the ShutdownReason is the [context.Cause] of a component's `Run` context
+// once it's cancelled, and it's also attached to the contexts passed to the component's shutdown (or `Stop`, for
+// [WithStartStop]), which aren't cancelled. Use [ShutdownReasonFromContext] to get at it either way.
+type ShutdownReason struct {
+	Trigger ShutdownTrigger
+
+	// The component that exited or failed, for [ShutdownComponentExited], [ShutdownComponentFailed], and
+	// [ShutdownTimeout] caused by a component.
+	Component string
+
+	// The underlying error: the reason passed to [Controller.RequestStop] (which may be nil), a [SignalError], the
+	// component's error, or the cause of the context passed to [NewController].
+	Err error
+
+	// For calls to [Controller.RequestStop], the stack of the calling goroutine, in the same format as an
+	// unrecovered panic. Useful to track down an unexpected stop request.
+	Stack string
+}
+
+func (r ShutdownReason) Error() string {
+	msg := "shutdown (" + r.Trigger.String() + ")"
+	if r.Err != nil {
+		msg += ": " + r.Err.Error()
+	}
+	return msg
+}
+
+func (r ShutdownReason) Unwrap() error {
+	return r.Err
+}
+
+func newShutdownReason(impl controller.ShutdownReason) ShutdownReason {
+	return ShutdownReason{
+		Trigger:   ShutdownTrigger(impl.Trigger),
+		Component: impl.Component,
+		Err:       impl.Err,
+		Stack:     impl.Stack,
+	}
+}
+
+// ShutdownReason returns the reason the controller is shutting down (or has shut down), and false if it hasn't been
+// asked to stop. It's typically checked after [Controller.Wait] returns.
+func (c *Controller) ShutdownReason() (ShutdownReason, bool) {
+	impl, ok := c.impl.ShutdownReason()
+	if !ok {
+		return ShutdownReason{}, false
+	}
+	return newShutdownReason(impl), true
+}
+
+// ShutdownReasonFromContext returns the [ShutdownReason] carried by a context passed to a component during the
+// controller's shutdown, and false if there's none. That's the case for the contexts of a component that's being
+// stopped individually (e.g. via [Controller.StopComponent]), or of a `Run` that hasn't been cancelled yet.
+func ShutdownReasonFromContext(ctx context.Context) (ShutdownReason, bool) {
+	cause := component.ShutdownCause(ctx)
+	if cause == nil {
+		cause = context.Cause(ctx)
+	}
+	var reason ShutdownReason
+	ok := errors.As(cause, &reason)
+	return reason, ok
+}
@@ -0,0 +1,61 @@
+package launch
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shoenig/test"
+	"github.com/spikesdivzero/launch-control/internal/component"
+)
+
+func TestShutdownReason_Error(t *testing.T) {
+	test.EqError(t, ShutdownReason{}, "shutdown (Requested)")
+
+	err := errors.New("hello")
+	reason := ShutdownReason{Trigger: ShutdownComponentExited, Component: "db", Err: err}
+	test.EqError(t, reason, "shutdown (ComponentExited): hello")
+	test.ErrorIs(t, reason, err)
+}
+
+func TestShutdownReasonFromContext(t *testing.T) {
+	reason := ShutdownReason{Trigger: ShutdownSignal, Err: SignalError{}}
+
+	t.Run("none", func(t *testing.T) {
+		_, ok := ShutdownReasonFromContext(t.Context())
+		test.False(t, ok)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		_, ok = ShutdownReasonFromContext(ctx)
+		test.False(t, ok)
+	})
+
+	t.Run("value", func(t *testing.T) {
+		got, ok := ShutdownReasonFromContext(component.WithShutdownCause(t.Context(), reason))
+		test.True(t, ok)
+		test.Eq(t, reason, got)
+	})
+
+	t.Run("cause", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(t.Context())
+		cancel(reason)
+		got, ok := ShutdownReasonFromContext(ctx)
+		test.True(t, ok)
+		test.Eq(t, reason, got)
+	})
+}
+
+func TestController_ShutdownReason(t *testing.T) {
+	ctrl := NewController(t.Context())
+	_, ok := ctrl.ShutdownReason()
+	test.False(t, ok)
+
+	err := errors.New("hello")
+	ctrl.RequestStop(err)
+	reason, ok := ctrl.ShutdownReason()
+	test.True(t, ok)
+	test.Eq(t, ShutdownRequested, reason.Trigger)
+	test.Eq(t, err, reason.Err)
+	test.StrContains(t, reason.Stack, "launch-control.TestController_ShutdownReason")
+}
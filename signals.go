@@ -0,0 +1,20 @@
+package launch
+
+import (
+	"os"
+
+	"github.com/spikesdivzero/launch-control/internal/controller"
+)
+
+// The process exit code used when a repeated signal escalates the shutdown. See [WithSignals].
+const EscalationExitCode = controller.EscalationExitCode
+
+// A SignalError is the reason passed to [Controller.RequestStop] when the controller is stopped by one of the
+// signals given to [WithSignals].
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e SignalError) Error() string {
+	return "received signal: " + e.Signal.String()
+}
@@ -0,0 +1,104 @@
+package launch
+
+import "time"
+
+// A Status is a point-in-time snapshot of a [Controller] and its components, as returned by [Controller.Status].
+//
+// It's a plain struct, intended to be rendered as-is (e.g. via encoding/json) for admin pages and logs.
+type Status struct {
+	// The controller's lifecycle state: one of "New", "Alive", "Dying", or "Dead".
+	State string `json:"state"`
+
+	// Set once the application has called [Controller.FinishStartup], after launching everything it needs to. It
+	// stays set from then on, and is never set if the controller started shutting down first.
+	StartupFinished bool `json:"startup_finished"`
+
+	// One entry per component, in launch order.
+	Components []ComponentStatus `json:"components"`
+}
+
+// Ready reports whether the controller is Alive, startup has finished, and every component counts as ready (see
+// [ComponentStatus.CountsAsReady]). This is the condition checked by [HealthHandler]'s `/readyz`.
+func (s Status) Ready() bool {
+	if s.State != "Alive" || !s.StartupFinished {
+		return false
+	}
+	for _, cs := range s.Components {
+		if !cs.CountsAsReady() {
+			return false
+		}
+	}
+	return true
+}
+
+// A ComponentStatus describes a single component within a [Status].
+//
+// Timestamps are left as the zero time if the component hasn't reached that point. If the component was restarted,
+// StartedAt and ReadyAt describe its most recent start.
+type ComponentStatus struct {
+	Name  string         `json:"name"`
+	State ComponentState `json:"state"`
+
+	// Set via [WithCriticality].
+	Criticality Criticality `json:"criticality"`
+
+	// The position of the component in the launch order, starting from 1. A component created by
+	// [Controller.Replace] takes over the position of the one it replaced.
+	LaunchOrder int `json:"launch_order"`
+
+	StartedAt time.Time `json:"started_at,omitzero"`
+	ReadyAt   time.Time `json:"ready_at,omitzero"`
+
+	// When the component reached a final state (see [ComponentHandle]).
+	StoppedAt time.Time `json:"stopped_at,omitzero"`
+
+	// The total number of CheckReady calls, across all starts of the component.
+	CheckReadyAttempts int `json:"check_ready_attempts"`
+
+	// The most recent error recorded for the component, if any.
+	LastError string `json:"last_error,omitempty"`
+}
+
+// CountsAsReady reports whether the component lets the application be ready: either it's [ComponentReady], or it's
+// an [Optional] component that has failed. The controller keeps running without a failed Optional component, so it
+// shouldn't keep the application out of service for good; its failure is still shown in LastError.
+func (cs ComponentStatus) CountsAsReady() bool {
+	switch cs.State {
+	case ComponentReady:
+		return true
+	case ComponentFailed:
+		return cs.Criticality == Optional
+	default:
+		return false
+	}
+}
+
+// Status returns a snapshot of the controller, and of every component it currently manages. Components that were
+// removed via [Controller.StopComponent] or [Controller.Replace] are not included.
+func (c *Controller) Status() Status {
+	impl := c.impl.Status()
+
+	s := Status{
+		State:           impl.State,
+		StartupFinished: impl.StartupFinished,
+		Components:      make([]ComponentStatus, 0, len(impl.Components)),
+	}
+	for _, cs := range impl.Components {
+		var lastErr string
+		if cs.LastError != nil {
+			lastErr = cs.LastError.Error()
+		}
+		s.Components = append(s.Components, ComponentStatus{
+			Name:               cs.Name,
+			State:              ComponentState(cs.State),
+			Criticality:        Criticality(cs.Criticality),
+			LaunchOrder:        cs.LaunchOrder,
+			StartedAt:          cs.StartedAt,
+			ReadyAt:            cs.ReadyAt,
+			StoppedAt:          cs.StoppedAt,
+			CheckReadyAttempts: cs.CheckReadyAttempts,
+			LastError:          lastErr,
+		})
+	}
+	return s
+}
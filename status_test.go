@@ -0,0 +1,95 @@
+package launch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+)
+
+func TestController_Status(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctrl := NewController(t.Context())
+		t0 := time.Now()
+
+		ctrl.Launch("ok", WithStartStop(
+			func(ctx context.Context) error { return nil },
+			func(ctx context.Context) error { return nil }))
+		ctrl.Launch("bad",
+			WithStartStop(
+				func(ctx context.Context) error { return nil },
+				func(ctx context.Context) error { return nil }),
+			WithCheckReady(func(ctx context.Context) (bool, error) { return false, errors.New("nope") }))
+		ctrl.Wait()
+
+		s := ctrl.Status()
+		test.Eq(t, Status{
+			State: "Dead",
+			Components: []ComponentStatus{
+				{
+					Name:        "ok",
+					State:       ComponentStopped,
+					LaunchOrder: 1,
+					StartedAt:   t0,
+					ReadyAt:     t0,
+					StoppedAt:   t0,
+				},
+				{
+					Name:               "bad",
+					State:              ComponentFailed,
+					LaunchOrder:        2,
+					StartedAt:          t0,
+					StoppedAt:          t0,
+					CheckReadyAttempts: 1,
+					LastError:          "component bad wait-ready: nope",
+				},
+			},
+		}, s)
+
+		// And it's serializable.
+		b, err := json.Marshal(s)
+		must.NoError(t, err)
+		var got Status
+		must.NoError(t, json.Unmarshal(b, &got))
+		test.Eq(t, s, got)
+	})
+}
+
+func TestStatus_Ready(t *testing.T) {
+	ready := ComponentStatus{Name: "a", State: ComponentReady}
+	starting := ComponentStatus{Name: "b", State: ComponentStarting}
+	failedOptional := ComponentStatus{Name: "c", State: ComponentFailed, Criticality: Optional}
+	failedCritical := ComponentStatus{Name: "d", State: ComponentFailed, Criticality: Critical}
+
+	test.False(t, Status{State: "New"}.Ready())
+	test.False(t, Status{State: "Alive", Components: []ComponentStatus{ready}}.Ready())
+	test.True(t, Status{State: "Alive", StartupFinished: true}.Ready())
+	test.True(t, Status{State: "Alive", StartupFinished: true, Components: []ComponentStatus{ready}}.Ready())
+	test.False(t, Status{State: "Alive", StartupFinished: true, Components: []ComponentStatus{ready, starting}}.Ready())
+	test.False(t, Status{State: "Dying", StartupFinished: true, Components: []ComponentStatus{ready}}.Ready())
+
+	alive := func(cs ...ComponentStatus) Status {
+		return Status{State: "Alive", StartupFinished: true, Components: cs}
+	}
+	test.True(t, alive(ready, failedOptional).Ready())
+	test.False(t, alive(ready, failedCritical).Ready())
+}
+
+func TestCriticality_Text(t *testing.T) {
+	for crit := Critical; crit <= Optional; crit++ {
+		text, err := crit.MarshalText()
+		must.NoError(t, err)
+
+		var got Criticality
+		must.NoError(t, got.UnmarshalText(text))
+		test.Eq(t, crit, got)
+	}
+
+	var got Criticality
+	test.EqError(t, got.UnmarshalText([]byte("Vital")), `unknown criticality "Vital"`)
+}
@@ -0,0 +1,217 @@
+package launch
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+	"github.com/spikesdivzero/launch-control/internal/sdnotify"
+)
+
+// Set in the environment of the new process by [Controller.Upgrade], naming the fd of the readiness pipe.
+const upgradeReadyFDEnv = "LAUNCH_UPGRADE_READY_FD"
+
+const upgradeReadyMsg = "ready\n"
+
+// An UpgradeError is the reason passed to [Controller.RequestStop] once [Controller.Upgrade] has handed over to the
+// new process.
+type UpgradeError struct {
+	PID int
+}
+
+func (e UpgradeError) Error() string {
+	return fmt.Sprintf("upgraded to a new process (pid %d)", e.PID)
+}
+
+var errUpgradeInProgress = errors.New("an upgrade is already in progress")
+
+// Only one upgrade may be in progress at a time, across all controllers, as the listeners belong to the process.
+var upgradeMu sync.Mutex
+
+// Upgrade starts a new copy of the application, hands it the listeners claimed via [WithListener] (whether inherited
+// or opened via [Listen]), and waits for it to finish starting up. Only once it's ready is this controller asked to
+// stop, with an [UpgradeError] as the reason, so that the old process drains through the normal shutdown while the
+// new one is already accepting connections on the same sockets.
+//
+// The new process runs the same executable with the same arguments, unless [WithUpgradeCommand] says otherwise. It
+// inherits the environment, along with the listeners in the same way as for systemd's socket activation (see
+// [Listeners]). It's considered ready once it has called [Controller.FinishStartup]; if it exits or fails before
+// then, or if ctx is done first, it's killed, and the error is returned, leaving this process running as it was.
+//
+// Under systemd (i.e. when $NOTIFY_SOCKET is set), the new process is then made the unit's main process via
+// MAINPID=, so that it isn't killed when this one exits. If that can't be sent, the upgrade fails as above.
+//
+// An error is also returned if the controller isn't running, or if another upgrade is already in progress.
+func (c *Controller) Upgrade(ctx context.Context) error {
+	if !upgradeMu.TryLock() {
+		return errUpgradeInProgress
+	}
+	defer upgradeMu.Unlock()
+
+	if !c.impl.IsAlive() {
+		return lcerrors.ErrControllerNotAlive
+	}
+
+	cmd, err := c.upgradeCommand()
+	if err != nil {
+		return fmt.Errorf("upgrade: %w", err)
+	}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("upgrade: %w", err)
+	}
+	defer readyR.Close()
+
+	cmd.Env = append(cmd.Env, upgradeReadyFDEnv+"="+strconv.Itoa(listenFDsStart+len(cmd.ExtraFiles)))
+	cmd.ExtraFiles = append(cmd.ExtraFiles, readyW)
+	err = cmd.Start()
+	for _, f := range cmd.ExtraFiles {
+		f.Close() // the child has its own copies now
+	}
+	if err != nil {
+		return fmt.Errorf("upgrade: %w", err)
+	}
+
+	log := c.impl.Log.With("pid", cmd.Process.Pid)
+	log.Info("upgrade started, waiting for the new process to become ready")
+
+	readyCh := make(chan error, 1)
+	go func() {
+		line, err := bufio.NewReader(readyR).ReadString('\n')
+		if line == upgradeReadyMsg {
+			readyCh <- nil
+		} else {
+			readyCh <- fmt.Errorf("new process exited before becoming ready: %w", err)
+		}
+	}()
+
+	select {
+	case err = <-readyCh:
+	case <-ctx.Done():
+		err = context.Cause(ctx)
+	}
+	if n := sdnotify.FromEnv(); err == nil && n != nil {
+		err = n.Send(sdnotify.MainPID(cmd.Process.Pid))
+	}
+	if err != nil {
+		log.Error("upgrade failed", "error", err)
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("upgrade: %w", err)
+	}
+
+	log.Info("upgrade ready, stopping")
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+	keepListenerPaths()
+	c.RequestStop(UpgradeError{pid})
+	return nil
+}
+
+func (c *Controller) upgradeCommand() (*exec.Cmd, error) {
+	path, args := c.impl.UpgradePath, c.impl.UpgradeArgs
+	if path == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+		path, args = exe, os.Args[1:]
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		// The new process becomes the watchdog's responsibility once it's the main process, rather than ours.
+		return strings.HasPrefix(kv, "LISTEN_") || strings.HasPrefix(kv, upgradeReadyFDEnv+"=") ||
+			strings.HasPrefix(kv, "WATCHDOG_PID=")
+	})
+
+	listeners, names, err := claimedListenerFiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(listeners) > 0 {
+		cmd.ExtraFiles = listeners
+		cmd.Env = append(cmd.Env,
+			"LISTEN_FDS="+strconv.Itoa(len(listeners)),
+			"LISTEN_FDNAMES="+strings.Join(names, ":"))
+	}
+	return cmd, nil
+}
+
+// Returns a copy of the file for each claimed listener, in name order.
+func claimedListenerFiles() ([]*os.File, []string, error) {
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+
+	var names []string
+	for name := range inherited.claimed {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	files := make([]*os.File, 0, len(names))
+	for _, name := range names {
+		ln, _ := lookupListener(name)
+		fl, ok := ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			return nil, nil, fmt.Errorf("listener %q can't be passed on", name)
+		}
+		f, err := fl.File()
+		if err != nil {
+			for _, f := range files {
+				f.Close()
+			}
+			return nil, nil, fmt.Errorf("listener %q: %w", name, err)
+		}
+		files = append(files, f)
+	}
+	return files, names, nil
+}
+
+// Keeps the unix sockets opened via Listen from being removed when we close them, as the new process is now using
+// them. (Inherited ones are never removed on close.)
+func keepListenerPaths() {
+	inherited.mu.Lock()
+	defer inherited.mu.Unlock()
+
+	for _, ln := range inherited.opened {
+		if ul, ok := ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+}
+
+var upgradeReadyOnce sync.Once
+
+// If this process was started by [Controller.Upgrade], lets the old process know that we're ready.
+func notifyUpgradeParent() error {
+	var err error
+	upgradeReadyOnce.Do(func() {
+		s := os.Getenv(upgradeReadyFDEnv)
+		if s == "" {
+			return
+		}
+		os.Unsetenv(upgradeReadyFDEnv)
+
+		fd, convErr := strconv.Atoi(s)
+		if convErr != nil {
+			err = fmt.Errorf("invalid %s %q", upgradeReadyFDEnv, s)
+			return
+		}
+		f := os.NewFile(uintptr(fd), "upgrade-ready")
+		defer f.Close()
+		_, err = f.WriteString(upgradeReadyMsg)
+	})
+	return err
+}
@@ -0,0 +1,200 @@
+package launch
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/shoenig/test"
+	"github.com/shoenig/test/must"
+	"github.com/spikesdivzero/launch-control/internal/lcerrors"
+)
+
+// Run as the new process by TestController_Upgrade. It serves a single connection on the inherited listener,
+// replying with its pid, and then stops.
+//
+// It calls Wait before launching anything, and takes a while to become ready, touching LAUNCH_TEST_UPGRADE_READY
+// once it is, so that the old process can tell it wasn't told about the upgrade too early.
+func TestUpgradeHelperProcess(t *testing.T) {
+	if os.Getenv("LAUNCH_TEST_UPGRADE_HELPER") == "" {
+		t.Skip("only run as a helper process for TestController_Upgrade")
+	}
+
+	// The address is ignored, as the listener is inherited from the old process.
+	_, err := Listen("http", "tcp", "127.0.0.1:0")
+	must.NoError(t, err)
+
+	ctrl := NewController(context.Background())
+	waitCh := make(chan error)
+	go func() { waitCh <- ctrl.Wait() }()
+
+	readyAt := time.Now().Add(200 * time.Millisecond)
+	stopCh := make(chan struct{})
+	ctrl.Launch("http", WithListener("http"),
+		WithCheckReadyBackoff(ConstBackoff(10*time.Millisecond)),
+		WithCheckReady(func(context.Context) (bool, error) {
+			if time.Now().Before(readyAt) {
+				return false, nil
+			}
+			return true, os.WriteFile(os.Getenv("LAUNCH_TEST_UPGRADE_READY"), nil, 0o600)
+		}),
+		WithRun(
+			func(ctx context.Context) error {
+				ln, _ := ListenerFromContext(ctx, "http")
+				conn, err := ln.Accept()
+				if err != nil {
+					return err
+				}
+				_, _ = io.WriteString(conn, strconv.Itoa(os.Getpid()))
+				conn.Close()
+
+				go ctrl.RequestStop(nil)
+				<-stopCh
+				return nil
+			},
+			func(context.Context) error {
+				close(stopCh)
+				return nil
+			}))
+	must.NoError(t, ctrl.FinishStartup())
+	must.NoError(t, <-waitCh)
+}
+
+func TestController_Upgrade(t *testing.T) {
+	// Keeps the old controller alive until it's asked to stop.
+	idle := func() ComponentOption {
+		stopCh := make(chan struct{})
+		return WithRun(
+			func(context.Context) error { <-stopCh; return nil },
+			func(context.Context) error { close(stopCh); return nil })
+	}
+
+	t.Run("hands over", func(t *testing.T) {
+		// This process wasn't socket-activated, so the listener is its own.
+		resetInherited(t, map[string]net.Listener{})
+		ln, err := Listen("http", "tcp", "127.0.0.1:0")
+		must.NoError(t, err)
+		// Socket paths are limited to ~100 bytes, which t.TempDir can exceed.
+		dir, err := os.MkdirTemp("", "sdnotify")
+		must.NoError(t, err)
+		defer os.RemoveAll(dir)
+		notifyPath := filepath.Join(dir, "notify.sock")
+		notifyConn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: notifyPath, Net: "unixgram"})
+		must.NoError(t, err)
+		defer notifyConn.Close()
+		t.Setenv("NOTIFY_SOCKET", notifyPath)
+		t.Setenv("LAUNCH_TEST_UPGRADE_HELPER", "1")
+		readyPath := filepath.Join(t.TempDir(), "ready")
+		t.Setenv("LAUNCH_TEST_UPGRADE_READY", readyPath)
+
+		ctrl := NewController(t.Context(),
+			WithUpgradeCommand(os.Args[0], "-test.run=^TestUpgradeHelperProcess$"))
+		ctrl.Launch("http", WithListener("http"), idle())
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+		defer cancel()
+		must.NoError(t, ctrl.Upgrade(ctx))
+
+		// The new process only reported that it was ready once its component was.
+		_, err = os.Stat(readyPath)
+		test.NoError(t, err)
+
+		var upgradeErr UpgradeError
+		must.ErrorAs(t, ctrl.Wait(), &upgradeErr)
+		test.NotEq(t, os.Getpid(), upgradeErr.PID)
+
+		// systemd was told that the new process is now the main one.
+		buf := make([]byte, 256)
+		must.NoError(t, notifyConn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		size, err := notifyConn.Read(buf)
+		must.NoError(t, err)
+		test.Eq(t, "MAINPID="+strconv.Itoa(upgradeErr.PID), string(buf[:size]))
+
+		// Only the new process is left listening.
+		ln.Close()
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		must.NoError(t, err)
+		defer conn.Close()
+		got, err := io.ReadAll(conn)
+		must.NoError(t, err)
+		test.Eq(t, strconv.Itoa(upgradeErr.PID), string(got))
+	})
+
+	t.Run("new process exits", func(t *testing.T) {
+		resetInherited(t, map[string]net.Listener{})
+
+		ctrl := NewController(t.Context(), WithUpgradeCommand("/bin/false"))
+		ctrl.Launch("idle", idle())
+
+		err := ctrl.Upgrade(t.Context())
+		test.EqError(t, err, "upgrade: new process exited before becoming ready: EOF")
+
+		// The old process carries on as before.
+		test.Eq(t, "Alive", ctrl.Status().State)
+		ctrl.RequestStop(nil)
+		must.NoError(t, ctrl.Wait())
+	})
+
+	t.Run("ctx done first", func(t *testing.T) {
+		resetInherited(t, map[string]net.Listener{})
+
+		ctrl := NewController(t.Context(), WithUpgradeCommand("/bin/sleep", "60"))
+		ctrl.Launch("idle", idle())
+
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+		test.ErrorIs(t, ctrl.Upgrade(ctx), context.DeadlineExceeded)
+
+		test.Eq(t, "Alive", ctrl.Status().State)
+		ctrl.RequestStop(nil)
+		must.NoError(t, ctrl.Wait())
+	})
+
+	t.Run("MAINPID can't be sent", func(t *testing.T) {
+		resetInherited(t, map[string]net.Listener{})
+		t.Setenv("NOTIFY_SOCKET", filepath.Join(t.TempDir(), "missing.sock"))
+
+		ctrl := NewController(t.Context(), WithUpgradeCommand(os.Args[0], "-test.run=^TestUpgradeHelperProcess$"))
+		ctrl.Launch("idle", idle())
+		t.Setenv("LAUNCH_TEST_UPGRADE_HELPER", "1")
+		t.Setenv("LAUNCH_TEST_UPGRADE_READY", filepath.Join(t.TempDir(), "ready"))
+
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+		defer cancel()
+		test.ErrorContains(t, ctrl.Upgrade(ctx), "upgrade: sd_notify: ")
+
+		test.Eq(t, "Alive", ctrl.Status().State)
+		ctrl.RequestStop(nil)
+		must.NoError(t, ctrl.Wait())
+	})
+
+	t.Run("not alive", func(t *testing.T) {
+		ctrl := NewController(t.Context())
+		ctrl.RequestStop(nil)
+		must.NoError(t, ctrl.Wait())
+		test.ErrorIs(t, ctrl.Upgrade(t.Context()), lcerrors.ErrControllerNotAlive)
+	})
+}
+
+func TestUpgradeError(t *testing.T) {
+	test.EqError(t, UpgradeError{PID: 1234}, "upgraded to a new process (pid 1234)")
+}
+
+func Test_keepListenerPaths(t *testing.T) {
+	resetInherited(t, map[string]net.Listener{})
+
+	path := filepath.Join(t.TempDir(), "s")
+	ln, err := Listen("unix", "unix", path)
+	must.NoError(t, err)
+
+	// The new process is still using the socket, so closing ours mustn't remove it.
+	keepListenerPaths()
+	must.NoError(t, ln.Close())
+	_, err = os.Stat(path)
+	test.NoError(t, err)
+}